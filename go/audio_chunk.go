@@ -0,0 +1,519 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// PCMAudio is uncompressed audio read from a WAV file.
+type PCMAudio struct {
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+	// Data holds interleaved little-endian samples.
+	Data []byte
+}
+
+// ParseWAV reads a PCM WAV file. Compressed WAV encodings are rejected.
+func ParseWAV(data []byte) (*PCMAudio, error) {
+	w, err := readWAV(data)
+	if err != nil {
+		return nil, err
+	}
+	if w.format != wavFormatPCM {
+		return nil, fmt.Errorf("wav: unsupported format tag %#x, want PCM", w.format)
+	}
+	a := &PCMAudio{Channels: w.channels, SampleRate: w.sampleRate, BitsPerSample: w.bitsPerSample}
+	switch a.BitsPerSample {
+	case 8, 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("wav: unsupported sample size %d bits", a.BitsPerSample)
+	}
+	if a.Channels == 0 || a.SampleRate == 0 {
+		return nil, errors.New("wav: bad fmt chunk")
+	}
+	a.Data = w.data[:len(w.data)-len(w.data)%a.frameSize()]
+	return a, nil
+}
+
+const (
+	wavFormatPCM        = 1
+	wavFormatExtensible = 0xFFFE
+)
+
+// wavSubFormatSuffix is the part of a WAVE_FORMAT_EXTENSIBLE sub-format GUID
+// that follows the format tag it wraps.
+var wavSubFormatSuffix = []byte("\x00\x00\x00\x00\x10\x00\x80\x00\x00\xAA\x00\x38\x9B\x71")
+
+// wavFile is the fmt chunk and sample data of a RIFF/WAVE file.
+type wavFile struct {
+	// format is the format tag, with WAVE_FORMAT_EXTENSIBLE resolved to the
+	// format it wraps.
+	format        uint16
+	channels      int
+	sampleRate    int
+	byteRate      int
+	bitsPerSample int
+	data          []byte
+}
+
+// readWAV reads the fmt and data chunks of a RIFF/WAVE file. A data chunk
+// that claims more bytes than the file holds is cut short.
+func readWAV(data []byte) (*wavFile, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, errors.New("wav: not a RIFF/WAVE file")
+	}
+	var w *wavFile
+	for rest := data[12:]; len(rest) >= 8; {
+		id, size := string(rest[:4]), int(binary.LittleEndian.Uint32(rest[4:]))
+		body := rest[8:]
+		size = min(size, len(body))
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, errors.New("wav: short fmt chunk")
+			}
+			w = &wavFile{
+				format:        binary.LittleEndian.Uint16(body),
+				channels:      int(binary.LittleEndian.Uint16(body[2:])),
+				sampleRate:    int(binary.LittleEndian.Uint32(body[4:])),
+				byteRate:      int(binary.LittleEndian.Uint32(body[8:])),
+				bitsPerSample: int(binary.LittleEndian.Uint16(body[14:])),
+			}
+			if w.format == wavFormatExtensible {
+				if size < 40 {
+					return nil, errors.New("wav: short WAVE_FORMAT_EXTENSIBLE fmt chunk")
+				}
+				guid := body[24:40]
+				if !bytes.Equal(guid[2:], wavSubFormatSuffix) {
+					return nil, errors.New("wav: unknown WAVE_FORMAT_EXTENSIBLE sub-format")
+				}
+				w.format = binary.LittleEndian.Uint16(guid)
+			}
+		case "data":
+			if w == nil {
+				return nil, errors.New("wav: data before fmt chunk")
+			}
+			w.data = body[:size]
+			return w, nil
+		}
+		rest = body[min(size+size%2, len(body)):]
+	}
+	return nil, errors.New("wav: no data chunk")
+}
+
+func (a *PCMAudio) frameSize() int {
+	return a.Channels * a.BitsPerSample / 8
+}
+
+// Frames returns the number of samples per channel.
+func (a *PCMAudio) Frames() int {
+	return len(a.Data) / a.frameSize()
+}
+
+// Duration returns the length of the audio.
+func (a *PCMAudio) Duration() time.Duration {
+	return a.timeAt(a.Frames())
+}
+
+func (a *PCMAudio) timeAt(frame int) time.Duration {
+	return time.Duration(int64(frame) * int64(time.Second) / int64(a.SampleRate))
+}
+
+func (a *PCMAudio) frameAt(d time.Duration) int {
+	return int(int64(d) * int64(a.SampleRate) / int64(time.Second))
+}
+
+// Slice returns the audio between start and end. The samples are shared.
+func (a *PCMAudio) Slice(start, end time.Duration) *PCMAudio {
+	from := min(max(a.frameAt(start), 0), a.Frames())
+	to := min(max(a.frameAt(end), from), a.Frames())
+	s := *a
+	s.Data = a.Data[from*a.frameSize() : to*a.frameSize()]
+	return &s
+}
+
+// WAV encodes the audio as a WAV file.
+func (a *PCMAudio) WAV() []byte {
+	var b bytes.Buffer
+	b.Grow(44 + len(a.Data))
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+len(a.Data)))
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, binary.LittleEndian, struct {
+		Size                      uint32
+		Format, Channels          uint16
+		SampleRate, ByteRate      uint32
+		BlockAlign, BitsPerSample uint16
+	}{16, 1, uint16(a.Channels), uint32(a.SampleRate), uint32(a.SampleRate * a.frameSize()), uint16(a.frameSize()), uint16(a.BitsPerSample)})
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(len(a.Data)))
+	b.Write(a.Data)
+	return b.Bytes()
+}
+
+// rms returns the RMS level of frames [from, to) across all channels as a
+// fraction of full scale.
+func (a *PCMAudio) rms(from, to int) float64 {
+	bytesPer := a.BitsPerSample / 8
+	data := a.Data[from*a.frameSize() : to*a.frameSize()]
+	var sum float64
+	n := len(data) / bytesPer
+	if n == 0 {
+		return 0
+	}
+	for i := 0; i+bytesPer <= len(data); i += bytesPer {
+		var v float64
+		switch bytesPer {
+		case 1: // 8-bit WAV is unsigned
+			v = (float64(data[i]) - 128) / 128
+		case 2:
+			v = float64(int16(binary.LittleEndian.Uint16(data[i:]))) / (1 << 15)
+		case 3:
+			s := int32(uint32(data[i])<<8|uint32(data[i+1])<<16|uint32(data[i+2])<<24) >> 8
+			v = float64(s) / (1 << 23)
+		case 4:
+			v = float64(int32(binary.LittleEndian.Uint32(data[i:]))) / (1 << 31)
+		}
+		sum += v * v
+	}
+	return math.Sqrt(sum / float64(n))
+}
+
+// AudioChunkOptions configures ChunkPCM.
+type AudioChunkOptions struct {
+	// Window is the longest chunk. Zero means 5 minutes.
+	Window time.Duration
+	// Overlap is repeated at the start of the next chunk when a cut falls
+	// mid-speech, so words on the boundary are heard whole. Zero means 2s.
+	Overlap time.Duration
+	// SplitOnSilence moves each cut to the longest pause in the last third
+	// of the window. Cuts made in a pause need no overlap.
+	SplitOnSilence bool
+	// SilenceThreshold is the RMS level, as a fraction of full scale, below
+	// which audio counts as silent. Zero means 0.01 (-40 dBFS).
+	SilenceThreshold float64
+	// MinSilence is the shortest pause that counts. Zero means 300ms.
+	MinSilence time.Duration
+}
+
+func (o *AudioChunkOptions) withDefaults() AudioChunkOptions {
+	var out AudioChunkOptions
+	if o != nil {
+		out = *o
+	}
+	if out.Window == 0 {
+		out.Window = 5 * time.Minute
+	}
+	if out.Overlap == 0 {
+		out.Overlap = 2 * time.Second
+	}
+	if out.SilenceThreshold == 0 {
+		out.SilenceThreshold = 0.01
+	}
+	if out.MinSilence == 0 {
+		out.MinSilence = 300 * time.Millisecond
+	}
+	return out
+}
+
+// AudioChunk is one piece of a longer recording.
+type AudioChunk struct {
+	// Start and End are offsets into the original recording.
+	Start, End time.Duration
+	Audio      *PCMAudio
+}
+
+// ChunkWAV parses a PCM WAV file and splits it with ChunkPCM.
+func ChunkWAV(data []byte, opts *AudioChunkOptions) ([]AudioChunk, error) {
+	a, err := ParseWAV(data)
+	if err != nil {
+		return nil, err
+	}
+	return ChunkPCM(a, opts)
+}
+
+// ChunkPCM splits audio into windows of at most opts.Window. Consecutive
+// chunks overlap by opts.Overlap unless the cut falls in a pause.
+func ChunkPCM(a *PCMAudio, opts *AudioChunkOptions) ([]AudioChunk, error) {
+	o := opts.withDefaults()
+	if o.Overlap >= o.Window {
+		return nil, fmt.Errorf("chunk audio: overlap %v must be shorter than window %v", o.Overlap, o.Window)
+	}
+	total, win, overlap := a.Frames(), max(a.frameAt(o.Window), 1), a.frameAt(o.Overlap)
+	var chunks []AudioChunk
+	add := func(from, to int) {
+		s := *a
+		s.Data = a.Data[from*a.frameSize() : to*a.frameSize()]
+		chunks = append(chunks, AudioChunk{Start: a.timeAt(from), End: a.timeAt(to), Audio: &s})
+	}
+	for start := 0; start < total; {
+		end := start + win
+		if end >= total {
+			add(start, total)
+			break
+		}
+		next := end - overlap
+		if o.SplitOnSilence {
+			if cut, ok := a.findPause(start+win*2/3, end, a.frameAt(o.MinSilence), o.SilenceThreshold); ok {
+				end, next = cut, cut
+			}
+		}
+		add(start, end)
+		start = next
+	}
+	return chunks, nil
+}
+
+// findPause returns the middle of the longest run of silence between frames
+// from and to that lasts at least minFrames.
+func (a *PCMAudio) findPause(from, to, minFrames int, threshold float64) (int, bool) {
+	hop := max(a.SampleRate/100, 1) // 10ms
+	bestStart, bestLen, runStart := 0, 0, -1
+	for f := (from + hop - 1) / hop * hop; f+hop <= to; f += hop {
+		if a.rms(f, f+hop) < threshold {
+			if runStart < 0 {
+				runStart = f
+			}
+			if n := f + hop - runStart; n > bestLen {
+				bestStart, bestLen = runStart, n
+			}
+		} else {
+			runStart = -1
+		}
+	}
+	if bestLen == 0 || bestLen < minFrames {
+		return 0, false
+	}
+	return bestStart + bestLen/2, true
+}
+
+// TranscriptSegment is a span of transcribed speech.
+type TranscriptSegment struct {
+	// Start and End are offsets into the original recording.
+	Start, End time.Duration
+	Text       string
+}
+
+// Transcript is the stitched transcription of a chunked recording.
+type Transcript struct {
+	Segments []TranscriptSegment
+}
+
+// Text joins the segments into one string.
+func (t *Transcript) Text() string {
+	texts := make([]string, len(t.Segments))
+	for i, s := range t.Segments {
+		texts[i] = s.Text
+	}
+	return strings.Join(texts, " ")
+}
+
+// AudioTranscriber transcribes recordings too long for a single request by
+// chunking them and stitching the results.
+type AudioTranscriber struct {
+	Client *genai.Client
+	Model  string
+	// Chunks configures how the recording is split.
+	Chunks *AudioChunkOptions
+	// Concurrency is the number of chunks transcribed at once. Zero means 4.
+	Concurrency int
+	// Parts turns each chunk into a part. Zero value sends chunks inline when
+	// they are small enough and uploads them otherwise.
+	Parts *PartBuilder
+}
+
+// TranscribeWAV transcribes a PCM WAV recording.
+func (t *AudioTranscriber) TranscribeWAV(ctx context.Context, data []byte) (*Transcript, error) {
+	chunks, err := ChunkWAV(data, t.Chunks)
+	if err != nil {
+		return nil, err
+	}
+	parts := t.Parts
+	if parts == nil {
+		parts = &PartBuilder{Client: t.Client}
+	}
+	n := t.Concurrency
+	if n <= 0 {
+		n = 4
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	segments := make([][]TranscriptSegment, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i, c := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			segs, err := t.transcribeChunk(ctx, parts, c)
+			if err != nil {
+				errs[i] = fmt.Errorf("audio %v-%v: %w", c.Start, c.End, err)
+				cancel()
+				return
+			}
+			segments[i] = segs
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return &Transcript{Segments: stitchSegments(chunks, segments)}, nil
+}
+
+var transcriptSchema = &genai.Schema{
+	Type: genai.TypeArray,
+	Items: &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"start": {Type: genai.TypeNumber, Description: "Seconds from the start of the clip."},
+			"end":   {Type: genai.TypeNumber, Description: "Seconds from the start of the clip."},
+			"text":  {Type: genai.TypeString},
+		},
+		Required: []string{"start", "end", "text"},
+	},
+}
+
+func (t *AudioTranscriber) transcribeChunk(ctx context.Context, parts *PartBuilder, c AudioChunk) ([]TranscriptSegment, error) {
+	part, err := parts.NewRequest().FromBytes(ctx, c.Audio.WAV(), "audio/wav")
+	if err != nil {
+		return nil, err
+	}
+	contents := []*genai.Content{
+		genai.NewContentFromParts([]*genai.Part{
+			part,
+			genai.NewPartFromText("Transcribe this audio clip. Return one entry per sentence with its start and end time in seconds from the beginning of the clip."),
+		}, "user"),
+	}
+	resp, err := t.Client.Models.GenerateContent(ctx, t.Model, contents, &genai.GenerateContentConfig{
+		ResponseMIMEType: "application/json",
+		ResponseSchema:   transcriptSchema,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var raw []struct {
+		Start, End float64
+		Text       string
+	}
+	if err := json.Unmarshal([]byte(resp.Text()), &raw); err != nil {
+		return nil, fmt.Errorf("decode transcript: %w", err)
+	}
+	segs := make([]TranscriptSegment, 0, len(raw))
+	for _, r := range raw {
+		offset := func(sec float64) time.Duration {
+			d := time.Duration(sec * float64(time.Second))
+			return c.Start + min(max(d, 0), c.End-c.Start)
+		}
+		segs = append(segs, TranscriptSegment{Start: offset(r.Start), End: offset(r.End), Text: strings.TrimSpace(r.Text)})
+	}
+	return segs, nil
+}
+
+// stitchSegments merges per-chunk segments, whose times are already
+// absolute. Where chunks overlap, each side keeps the segments that start on
+// its half of the overlap; a segment repeated across the boundary is kept
+// once.
+func stitchSegments(chunks []AudioChunk, segments [][]TranscriptSegment) []TranscriptSegment {
+	var out []TranscriptSegment
+	for i, segs := range segments {
+		lo, hi := time.Duration(math.MinInt64), time.Duration(math.MaxInt64)
+		if i > 0 {
+			lo = (chunks[i].Start + chunks[i-1].End) / 2
+		}
+		if i+1 < len(chunks) {
+			hi = (chunks[i+1].Start + chunks[i].End) / 2
+		}
+		for _, s := range segs {
+			if s.Start < lo || s.Start >= hi {
+				continue
+			}
+			if n := len(out); n > 0 && s.Start < out[n-1].End && strings.EqualFold(s.Text, out[n-1].Text) {
+				continue
+			}
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func TextGenMultimodalAudioChunked() (*Transcript, error) {
+	// [START text_gen_multimodal_audio_chunked]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Record something to transcribe: a few paragraphs read aloud by a
+	// text-to-speech model, which returns 16-bit mono PCM at 24 kHz.
+	script := "The Eagle has landed. Houston, Tranquility Base here. " +
+		"Roger, Tranquility, we copy you on the ground. You got a bunch of guys about to turn blue. " +
+		"We're breathing again. Thanks a lot. " +
+		"That may have seemed like a very long final phase. The auto targeting was taking us " +
+		"right into a football field sized crater, with a large number of big boulders and rocks. " +
+		"It required us to go in manually over the rock field to find a reasonably good area."
+	speech, err := client.Models.GenerateContent(ctx, "gemini-2.5-flash-preview-tts",
+		genai.Text("Read this slowly, pausing between sentences: "+script),
+		&genai.GenerateContentConfig{
+			ResponseModalities: []string{"AUDIO"},
+			SpeechConfig: &genai.SpeechConfig{
+				VoiceConfig: &genai.VoiceConfig{
+					PrebuiltVoiceConfig: &genai.PrebuiltVoiceConfig{VoiceName: "Kore"},
+				},
+			},
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(speech.Candidates) == 0 || speech.Candidates[0].Content == nil ||
+		len(speech.Candidates[0].Content.Parts) == 0 || speech.Candidates[0].Content.Parts[0].InlineData == nil {
+		log.Fatal("no audio returned")
+	}
+	recording := &PCMAudio{
+		SampleRate:    24000,
+		Channels:      1,
+		BitsPerSample: 16,
+		Data:          speech.Candidates[0].Content.Parts[0].InlineData.Data,
+	}
+	slog.Info("Recorded", "duration", recording.Duration().Round(time.Second))
+
+	// Cut the recording at pauses near every 15 seconds, falling back to
+	// overlapping windows where nobody stops talking.
+	transcriber := &AudioTranscriber{
+		Client: client,
+		Model:  "gemini-2.0-flash",
+		Chunks: &AudioChunkOptions{Window: 15 * time.Second, Overlap: 2 * time.Second, SplitOnSilence: true},
+	}
+	transcript, err := transcriber.TranscribeWAV(ctx, recording.WAV())
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, s := range transcript.Segments {
+		slog.Info("Segment", "start", s.Start.Round(time.Second), "end", s.End.Round(time.Second), "text", s.Text)
+	}
+	// [END text_gen_multimodal_audio_chunked]
+	return transcript, err
+}
@@ -0,0 +1,190 @@
+package examples
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// labelledPCM returns 16-bit mono audio at 1 kHz in which every sample of
+// second s has the value 100*(s+1), except for the silent seconds listed.
+func labelledPCM(seconds int, silent ...int) *PCMAudio {
+	const rate = 1000
+	a := &PCMAudio{SampleRate: rate, Channels: 1, BitsPerSample: 16, Data: make([]byte, seconds*rate*2)}
+	for s := range seconds {
+		v := uint16(100 * (s + 1))
+		for _, q := range silent {
+			if q == s {
+				v = 0
+			}
+		}
+		for i := range rate {
+			binary.LittleEndian.PutUint16(a.Data[(s*rate+i)*2:], v)
+		}
+	}
+	return a
+}
+
+func TestParseWAVRoundTrip(t *testing.T) {
+	a, err := ParseWAV(testWAV(8000, 3*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.SampleRate != 8000 || a.Channels != 1 || a.BitsPerSample != 16 || a.Duration() != 3*time.Second {
+		t.Errorf("got %+v, duration %v", a, a.Duration())
+	}
+	b, err := ParseWAV(labelledPCM(2).WAV())
+	if err != nil || b.Frames() != 2000 || b.Slice(time.Second, 5*time.Second).Frames() != 1000 {
+		t.Errorf("round trip: %v", err)
+	}
+	// An odd-sized chunk truncated by the end of the file must not panic.
+	odd := append([]byte("RIFF\x00\x00\x00\x00WAVEjunk\x05\x00\x00\x00"), 'x')
+	if _, err := ParseWAV(odd); err == nil {
+		t.Error("ParseWAV accepted a file without data")
+	}
+	if _, err := wavDuration(odd); err == nil {
+		t.Error("wavDuration accepted a file without data")
+	}
+}
+
+// extensibleWAV returns a WAVE_FORMAT_EXTENSIBLE file whose sub-format GUID
+// starts with format.
+func extensibleWAV(format uint16) []byte {
+	fmtChunk := binary.LittleEndian.AppendUint16(nil, 0xFFFE)
+	fmtChunk = binary.LittleEndian.AppendUint16(fmtChunk, 1)
+	fmtChunk = binary.LittleEndian.AppendUint32(fmtChunk, 8000)
+	fmtChunk = binary.LittleEndian.AppendUint32(fmtChunk, 16000)
+	fmtChunk = binary.LittleEndian.AppendUint16(fmtChunk, 2)
+	fmtChunk = binary.LittleEndian.AppendUint16(fmtChunk, 16)
+	fmtChunk = binary.LittleEndian.AppendUint16(fmtChunk, 22)
+	fmtChunk = binary.LittleEndian.AppendUint16(fmtChunk, 16)
+	fmtChunk = binary.LittleEndian.AppendUint32(fmtChunk, 4)
+	fmtChunk = binary.LittleEndian.AppendUint16(fmtChunk, format)
+	fmtChunk = append(fmtChunk, wavSubFormatSuffix...)
+
+	b := []byte("RIFF\x00\x00\x00\x00WAVEfmt ")
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(fmtChunk)))
+	b = append(b, fmtChunk...)
+	b = append(b, "data"...)
+	b = binary.LittleEndian.AppendUint32(b, 16000)
+	return append(b, make([]byte, 16000)...)
+}
+
+func TestParseWAVExtensible(t *testing.T) {
+	a, err := ParseWAV(extensibleWAV(1))
+	if err != nil || a.Duration() != time.Second {
+		t.Errorf("PCM sub-format: %+v, %v", a, err)
+	}
+	// 3 is IEEE float, which ParseWAV can't decode but wavDuration can time.
+	if _, err := ParseWAV(extensibleWAV(3)); err == nil {
+		t.Error("ParseWAV accepted a float sub-format")
+	}
+	if d, err := wavDuration(extensibleWAV(3)); err != nil || d != time.Second {
+		t.Errorf("wavDuration = %v, %v; want 1s", d, err)
+	}
+	bad := extensibleWAV(1)
+	bad[len("RIFF....WAVEfmt ....")+39] ^= 0xFF
+	if _, err := ParseWAV(bad); err == nil {
+		t.Error("ParseWAV accepted an unknown sub-format GUID")
+	}
+}
+
+func TestChunkPCMFixedWindows(t *testing.T) {
+	chunks, err := ChunkPCM(labelledPCM(20), &AudioChunkOptions{Window: 5 * time.Second, Overlap: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range chunks {
+		got = append(got, fmt.Sprintf("%v-%v", c.Start, c.End))
+	}
+	if want := "0s-5s 4s-9s 8s-13s 12s-17s 16s-20s"; strings.Join(got, " ") != want {
+		t.Errorf("chunks = %v, want %v", got, want)
+	}
+	if chunks[1].Audio.Duration() != 5*time.Second {
+		t.Errorf("chunk audio is %v long", chunks[1].Audio.Duration())
+	}
+	if _, err := ChunkPCM(labelledPCM(2), &AudioChunkOptions{Window: time.Second, Overlap: time.Second}); err == nil {
+		t.Error("overlap equal to window was accepted")
+	}
+}
+
+func TestChunkPCMSplitsOnSilence(t *testing.T) {
+	chunks, err := ChunkPCM(labelledPCM(20, 7), &AudioChunkOptions{
+		Window: 10 * time.Second, Overlap: time.Second, SplitOnSilence: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range chunks {
+		got = append(got, fmt.Sprintf("%v-%v", c.Start, c.End))
+	}
+	// The first cut lands in the pause; the second has none to use.
+	if want := "0s-7.5s 7.5s-17.5s 16.5s-20s"; strings.Join(got, " ") != want {
+		t.Errorf("chunks = %v, want %v", got, want)
+	}
+}
+
+func TestAudioTranscriberStitches(t *testing.T) {
+	// The fake transcribes each whole second of a clip as "s<N>", where N is
+	// read back from the sample values, so it reports absolute positions.
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Contents []struct {
+				Parts []struct {
+					InlineData *struct {
+						Data []byte `json:"data"`
+					} `json:"inlineData"`
+				} `json:"parts"`
+			} `json:"contents"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		a, err := ParseWAV(req.Contents[0].Parts[0].InlineData.Data)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+			return
+		}
+		var segs []map[string]any
+		for k := 0; k*a.SampleRate < a.Frames(); k++ {
+			label := int(binary.LittleEndian.Uint16(a.Data[k*a.SampleRate*2:]))/100 - 1
+			segs = append(segs, map[string]any{"start": k, "end": k + 1, "text": fmt.Sprintf("s%d", label)})
+		}
+		out, _ := json.Marshal(segs)
+		writeJSON(w, http.StatusOK, textResponse(string(out)))
+	}))
+
+	tr := &AudioTranscriber{
+		Client: client,
+		Model:  "gemini-2.0-flash",
+		Chunks: &AudioChunkOptions{Window: 5 * time.Second, Overlap: time.Second},
+	}
+	transcript, err := tr.TranscribeWAV(context.Background(), labelledPCM(20).WAV())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []string
+	for s := range 20 {
+		want = append(want, fmt.Sprintf("s%d", s))
+	}
+	if got := transcript.Text(); got != strings.Join(want, " ") {
+		t.Errorf("Text = %q", got)
+	}
+	for i, s := range transcript.Segments {
+		if s.Start != time.Duration(i)*time.Second {
+			t.Errorf("segment %q starts at %v, want %ds", s.Text, s.Start, i)
+		}
+	}
+}
+
+func TestTextGenMultimodalAudioChunked(t *testing.T) {
+	_, err := TextGenMultimodalAudioChunked()
+	if err != nil {
+		t.Errorf("TextGenMultimodalAudioChunked returned an error: %v", err)
+	}
+}
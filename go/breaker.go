@@ -0,0 +1,161 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// ErrCircuitOpen is returned when a call is refused because its circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState is the state of one circuit.
+type BreakerState int
+
+const (
+	// BreakerClosed lets every call through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen refuses calls until the cooldown has passed.
+	BreakerOpen
+	// BreakerHalfOpen lets a single probe call through to test recovery.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+// CircuitBreaker keeps one circuit per key, usually a model name or an
+// endpoint/model pair. A circuit opens after FailureThreshold consecutive
+// failures and stays open for Cooldown. It is safe for concurrent use.
+type CircuitBreaker struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	now      func() time.Time
+}
+
+type circuit struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker returns a breaker that trips after threshold consecutive
+// failures and retries after cooldown.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		FailureThreshold: threshold,
+		Cooldown:         cooldown,
+		circuits:         make(map[string]*circuit),
+		now:              time.Now,
+	}
+}
+
+func (cb *CircuitBreaker) circuit(key string) *circuit {
+	c, ok := cb.circuits[key]
+	if !ok {
+		c = &circuit{}
+		cb.circuits[key] = c
+	}
+	if c.state == BreakerOpen && cb.now().Sub(c.openedAt) >= cb.Cooldown {
+		c.state = BreakerHalfOpen
+		c.probing = false
+	}
+	return c
+}
+
+// State returns the current state of the circuit for key.
+func (cb *CircuitBreaker) State(key string) BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.circuit(key).state
+}
+
+// Allow reports whether a call for key may proceed. Every allowed call must
+// be followed by a call to Record.
+func (cb *CircuitBreaker) Allow(key string) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.circuit(key)
+	switch c.state {
+	case BreakerOpen:
+		return fmt.Errorf("%s: %w", key, ErrCircuitOpen)
+	case BreakerHalfOpen:
+		if c.probing {
+			return fmt.Errorf("%s: %w", key, ErrCircuitOpen)
+		}
+		c.probing = true
+	}
+	return nil
+}
+
+// Record reports the outcome of a call allowed for key.
+func (cb *CircuitBreaker) Record(key string, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.circuit(key)
+	if errors.Is(err, context.Canceled) {
+		// A cancelled call says nothing about the backend.
+		c.probing = false
+		return
+	}
+	if !isBreakerFailure(err) {
+		c.state = BreakerClosed
+		c.failures = 0
+		c.probing = false
+		return
+	}
+	c.failures++
+	if c.state == BreakerHalfOpen || c.failures >= cb.FailureThreshold {
+		c.state = BreakerOpen
+		c.openedAt = cb.now()
+		c.probing = false
+	}
+}
+
+// GenerateContent calls GenerateContent for model unless its circuit is open.
+func (cb *CircuitBreaker) GenerateContent(
+	ctx context.Context,
+	client *genai.Client,
+	model string,
+	contents []*genai.Content,
+	config *genai.GenerateContentConfig,
+) (*genai.GenerateContentResponse, error) {
+	if err := cb.Allow(model); err != nil {
+		return nil, err
+	}
+	resp, err := client.Models.GenerateContent(ctx, model, contents, config)
+	cb.Record(model, err)
+	return resp, err
+}
+
+// isBreakerFailure reports whether err says something about the health of
+// the backend. Client errors do not count against it.
+func isBreakerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestCircuitBreakerTripsAndRecovers(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)}
+	cb := NewCircuitBreaker(3, time.Minute)
+	cb.now = clock.Now
+	overloaded := genai.APIError{Code: http.StatusServiceUnavailable, Status: "UNAVAILABLE"}
+
+	for i := 0; i < 3; i++ {
+		if err := cb.Allow("m"); err != nil {
+			t.Fatalf("call %d refused: %v", i, err)
+		}
+		cb.Record("m", overloaded)
+	}
+	if got := cb.State("m"); got != BreakerOpen {
+		t.Fatalf("state = %v, want open", got)
+	}
+	if err := cb.Allow("m"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow = %v, want ErrCircuitOpen", err)
+	}
+
+	clock.Advance(time.Minute)
+	if got := cb.State("m"); got != BreakerHalfOpen {
+		t.Fatalf("state = %v, want half-open", got)
+	}
+	if err := cb.Allow("m"); err != nil {
+		t.Fatalf("probe refused: %v", err)
+	}
+	if err := cb.Allow("m"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second probe = %v, want ErrCircuitOpen", err)
+	}
+	cb.Record("m", nil)
+	if got := cb.State("m"); got != BreakerClosed {
+		t.Fatalf("state = %v, want closed", got)
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	cb := NewCircuitBreaker(1, time.Minute)
+	cb.Record("m", genai.APIError{Code: http.StatusBadRequest, Status: "INVALID_ARGUMENT"})
+	cb.Record("m", context.Canceled)
+	if got := cb.State("m"); got != BreakerClosed {
+		t.Errorf("state = %v, want closed", got)
+	}
+}
+
+func TestCircuitBreakerGenerateContent(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		writeAPIError(w, http.StatusInternalServerError, "INTERNAL", "boom")
+	}))
+	cb := NewCircuitBreaker(2, time.Hour)
+
+	for i := 0; i < 4; i++ {
+		cb.GenerateContent(context.Background(), client, "gemini-2.0-flash", genai.Text("hi"), nil)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server called %d times, want 2", got)
+	}
+}
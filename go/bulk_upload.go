@@ -0,0 +1,366 @@
+package examples
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// ManifestEntry records the upload of one local file.
+type ManifestEntry struct {
+	Path           string     `json:"path"`
+	Name           string     `json:"name,omitempty"`
+	URI            string     `json:"uri,omitempty"`
+	MIMEType       string     `json:"mimeType,omitempty"`
+	ExpirationTime *time.Time `json:"expirationTime,omitempty"`
+	// Err is the reason the last upload attempt failed.
+	Err string `json:"error,omitempty"`
+}
+
+// UploadManifest maps local paths to uploaded files, sorted by path.
+type UploadManifest struct {
+	Entries []ManifestEntry `json:"entries"`
+}
+
+// Parts returns a file part for every uploaded entry, ready to be combined
+// with a text prompt.
+func (m *UploadManifest) Parts() []*genai.Part {
+	var parts []*genai.Part
+	for _, e := range m.Entries {
+		if e.Err == "" && e.URI != "" {
+			parts = append(parts, genai.NewPartFromURI(e.URI, e.MIMEType))
+		}
+	}
+	return parts
+}
+
+// Lookup returns the entry for path.
+func (m *UploadManifest) Lookup(path string) (ManifestEntry, bool) {
+	i := slices.IndexFunc(m.Entries, func(e ManifestEntry) bool { return e.Path == path })
+	if i < 0 {
+		return ManifestEntry{}, false
+	}
+	return m.Entries[i], true
+}
+
+// BulkUploader uploads many files with bounded concurrency.
+type BulkUploader struct {
+	Client *genai.Client
+	// Concurrency is the number of uploads in flight. Zero means 4.
+	Concurrency int
+	// ManifestPath, if set, is where the manifest is saved after every
+	// upload. A later run with the same path skips files that were uploaded
+	// and still exist and have not expired, so an interrupted batch can be
+	// resumed.
+	ManifestPath string
+	// Uploader, if set, is used to deduplicate uploads by content.
+	Uploader *FileUploader
+	// Model, if set, rejects files that model cannot read before uploading.
+	Model string
+
+	now func() time.Time
+}
+
+// UploadDir uploads every regular file under dir, skipping hidden files.
+func (b *BulkUploader) UploadDir(ctx context.Context, dir string) (*UploadManifest, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		hidden := strings.HasPrefix(d.Name(), ".") && path != dir
+		switch {
+		case d.IsDir() && hidden:
+			return filepath.SkipDir
+		case d.Type().IsRegular() && !hidden:
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bulk upload: %w", err)
+	}
+	return b.UploadPaths(ctx, paths)
+}
+
+// UploadGlob uploads the files matching pattern, as in filepath.Glob.
+func (b *BulkUploader) UploadGlob(ctx context.Context, pattern string) (*UploadManifest, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("bulk upload: %w", err)
+	}
+	return b.UploadPaths(ctx, paths)
+}
+
+// UploadPaths uploads the given files and returns their manifest, including
+// entries for files that failed. The error joins every failure.
+func (b *BulkUploader) UploadPaths(ctx context.Context, paths []string) (*UploadManifest, error) {
+	now := b.now
+	if now == nil {
+		now = time.Now
+	}
+	manifest, err := b.loadManifest()
+	if err != nil {
+		return nil, err
+	}
+	fresh := func(expires *time.Time) bool {
+		return expires == nil || now().Add(time.Hour).Before(*expires)
+	}
+	done := make(map[string]ManifestEntry)
+	for _, e := range manifest.Entries {
+		if e.Err == "" && e.Name != "" && fresh(e.ExpirationTime) {
+			done[e.Path] = e
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		errs    []error
+		results = make(map[string]ManifestEntry)
+	)
+	n := b.Concurrency
+	if n <= 0 {
+		n = 4
+	}
+	sem := make(chan struct{}, n)
+	for _, e := range manifest.Entries {
+		results[e.Path] = e
+	}
+	for _, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				mu.Lock()
+				defer mu.Unlock()
+				// Keep an earlier upload; it may still be good next run.
+				if _, ok := done[path]; !ok {
+					results[path] = ManifestEntry{Path: path, Err: ctx.Err().Error()}
+				}
+				errs = append(errs, fmt.Errorf("%s: %w", path, ctx.Err()))
+				return
+			}
+
+			var entry ManifestEntry
+			var err error
+			if prev, ok := done[path]; ok {
+				entry, ok, err = b.verify(ctx, prev, fresh)
+				if ok || err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					results[path] = entry
+					if err != nil {
+						errs = append(errs, fmt.Errorf("%s: %w", path, err))
+					}
+					return
+				}
+			}
+			entry, err = b.uploadOne(ctx, path)
+			mu.Lock()
+			defer mu.Unlock()
+			results[path] = entry
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			}
+			if err := b.saveManifest(results); err != nil {
+				errs = append(errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := b.saveManifest(results); err != nil {
+		errs = append(errs, err)
+	}
+	requested := make(map[string]ManifestEntry, len(paths))
+	for _, path := range paths {
+		requested[path] = results[path]
+	}
+	return manifestFrom(requested), errors.Join(errs...)
+}
+
+func (b *BulkUploader) uploadOne(ctx context.Context, path string) (ManifestEntry, error) {
+	entry := ManifestEntry{Path: path, MIMEType: inferMIMEType(path)}
+	var err error
+	switch {
+	case entry.MIMEType == "":
+		err = errors.New("unknown MIME type")
+	case b.Model != "" && !slices.Contains(AcceptedMIMETypes(b.Model), entry.MIMEType):
+		err = &UnsupportedMIMETypeError{Path: path, MIMEType: entry.MIMEType, Model: b.Model, Allowed: AcceptedMIMETypes(b.Model)}
+	}
+	if err != nil {
+		entry.Err = err.Error()
+		return entry, err
+	}
+	cfg := &genai.UploadFileConfig{MIMEType: entry.MIMEType, DisplayName: filepath.Base(path)}
+	var f *genai.File
+	if b.Uploader != nil {
+		f, err = b.Uploader.UploadFromPath(ctx, path, cfg)
+	} else {
+		f, err = b.Client.Files.UploadFromPath(ctx, path, cfg)
+	}
+	if err != nil {
+		entry.Err = err.Error()
+		return entry, err
+	}
+	return entryFromFile(entry, f), nil
+}
+
+// verify checks that the file recorded in e still exists and has not
+// expired or failed. It returns false if the file has to be uploaded again.
+func (b *BulkUploader) verify(ctx context.Context, e ManifestEntry, fresh func(*time.Time) bool) (ManifestEntry, bool, error) {
+	f, err := b.Client.Files.Get(ctx, e.Name, nil)
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return e, false, nil
+	}
+	if err != nil {
+		return e, false, fmt.Errorf("get %s: %w", e.Name, err)
+	}
+	e = entryFromFile(e, f)
+	if f.State == genai.FileStateFailed || !fresh(e.ExpirationTime) {
+		return e, false, nil
+	}
+	return e, true, nil
+}
+
+// entryFromFile records the upload f in e.
+func entryFromFile(e ManifestEntry, f *genai.File) ManifestEntry {
+	e.Name, e.URI, e.ExpirationTime = f.Name, f.URI, nil
+	if !f.ExpirationTime.IsZero() {
+		expires := f.ExpirationTime
+		e.ExpirationTime = &expires
+	}
+	if f.MIMEType != "" {
+		e.MIMEType = f.MIMEType
+	}
+	return e
+}
+
+// inferMIMEType detects a file's MIME type from its content and extension.
+// It returns "" if the type is unknown.
+func inferMIMEType(path string) string {
+	head, err := readHead(path)
+	if err != nil {
+		return ""
+	}
+	t := normalizeMIMEType(DetectMIMEType(path, head))
+	if t == "application/octet-stream" {
+		return ""
+	}
+	return t
+}
+
+func manifestFrom(results map[string]ManifestEntry) *UploadManifest {
+	m := &UploadManifest{Entries: make([]ManifestEntry, 0, len(results))}
+	for _, e := range results {
+		m.Entries = append(m.Entries, e)
+	}
+	slices.SortFunc(m.Entries, func(a, b ManifestEntry) int { return strings.Compare(a.Path, b.Path) })
+	return m
+}
+
+func (b *BulkUploader) loadManifest() (*UploadManifest, error) {
+	m := &UploadManifest{}
+	if b.ManifestPath == "" {
+		return m, nil
+	}
+	data, err := os.ReadFile(b.ManifestPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bulk upload manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("bulk upload manifest %s: %w", b.ManifestPath, err)
+	}
+	return m, nil
+}
+
+func (b *BulkUploader) saveManifest(results map[string]ManifestEntry) error {
+	if b.ManifestPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(manifestFrom(results), "", "  ")
+	if err != nil {
+		return fmt.Errorf("bulk upload manifest: %w", err)
+	}
+	// A temporary file of our own, so uploaders sharing the manifest never
+	// write to the same file.
+	tmp, err := os.CreateTemp(filepath.Dir(b.ManifestPath), filepath.Base(b.ManifestPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("bulk upload manifest: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("bulk upload manifest: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("bulk upload manifest: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), b.ManifestPath); err != nil {
+		return fmt.Errorf("bulk upload manifest: %w", err)
+	}
+	return nil
+}
+
+func FilesBulkUpload() (*genai.GenerateContentResponse, error) {
+	// [START files_bulk_upload]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Rerunning after a failure only uploads the files that are missing.
+	uploader := &BulkUploader{
+		Client:       client,
+		Concurrency:  4,
+		ManifestPath: filepath.Join(os.TempDir(), "bulk_upload_manifest.json"),
+	}
+	manifest, err := uploader.UploadGlob(ctx, filepath.Join(getMedia(), "*.jpg"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, e := range manifest.Entries {
+		slog.Info("Uploaded", "path", filepath.Base(e.Path), "name", e.Name)
+	}
+
+	parts := append([]*genai.Part{
+		genai.NewPartFromText("What is the difference between the instruments in these images?"),
+	}, manifest.Parts()...)
+	contents := []*genai.Content{
+		genai.NewContentFromParts(parts, "user"),
+	}
+
+	response, err := client.Models.GenerateContent(ctx, "gemini-2.0-flash", contents, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printResponse(response)
+	// [END files_bulk_upload]
+	return response, err
+}
@@ -0,0 +1,180 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyUploads fails the upload start of any file whose display name is in
+// fail, and records the peak number of concurrent uploads.
+type flakyUploads struct {
+	next http.Handler
+
+	mu       sync.Mutex
+	fail     map[string]bool
+	inFlight int
+	peak     int
+}
+
+func (f *flakyUploads) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, "/upload/v1beta/files") {
+		f.next.ServeHTTP(w, r)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	var req struct {
+		File struct {
+			DisplayName string `json:"displayName"`
+		} `json:"file"`
+	}
+	json.Unmarshal(body, &req)
+
+	f.mu.Lock()
+	fail := f.fail[req.File.DisplayName]
+	f.inFlight++
+	f.peak = max(f.peak, f.inFlight)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	if fail {
+		writeAPIError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "bad file")
+		return
+	}
+	f.next.ServeHTTP(w, r)
+}
+
+func TestBulkUploaderResume(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.txt":        "a",
+		"b.txt":        "b",
+		"c.jpg":        "c",
+		"d.pdf":        "%PDF-1.4",
+		".hidden.txt":  "hidden",
+		"e.unknownext": "\x00\x01\x02",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := newFakeFiles()
+	flaky := &flakyUploads{next: files, fail: map[string]bool{"b.txt": true}}
+	client := newTestClient(t, flaky)
+	b := &BulkUploader{Client: client, Concurrency: 2, ManifestPath: filepath.Join(t.TempDir(), "manifest.json")}
+	ctx := context.Background()
+
+	manifest, err := b.UploadDir(ctx, dir)
+	if err == nil || !strings.Contains(err.Error(), "b.txt") || !strings.Contains(err.Error(), "unknown MIME type") {
+		t.Errorf("err = %v, want failures for b.txt and e.unknownext", err)
+	}
+	if len(manifest.Entries) != 5 || len(manifest.Parts()) != 3 {
+		t.Fatalf("manifest = %+v", manifest.Entries)
+	}
+	if e, _ := manifest.Lookup(filepath.Join(dir, "c.jpg")); e.MIMEType != "image/jpeg" || e.URI == "" {
+		t.Errorf("c.jpg entry = %+v", e)
+	}
+	if flaky.peak > 2 {
+		t.Errorf("peak concurrency %d, want at most 2", flaky.peak)
+	}
+
+	// The second run only retries the failed file.
+	flaky.fail = nil
+	manifest, err = b.UploadGlob(ctx, filepath.Join(dir, "?.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files.uploadCount() != 4 {
+		t.Errorf("uploads = %d, want 4", files.uploadCount())
+	}
+	if len(manifest.Entries) != 2 || len(manifest.Parts()) != 2 {
+		t.Errorf("manifest = %+v", manifest.Entries)
+	}
+}
+
+func TestBulkUploaderResumeReuploadsMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := newFakeFiles()
+	client := newTestClient(t, files)
+	b := &BulkUploader{Client: client, ManifestPath: filepath.Join(t.TempDir(), "manifest.json")}
+	ctx := context.Background()
+	first, err := b.UploadDir(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a.txt was deleted remotely since the first run.
+	gone, _ := first.Lookup(filepath.Join(dir, "a.txt"))
+	if _, err := client.Files.Delete(ctx, gone.Name, nil); err != nil {
+		t.Fatal(err)
+	}
+	second, err := b.UploadDir(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files.uploadCount() != 3 {
+		t.Errorf("uploads = %d, want 3", files.uploadCount())
+	}
+	if e, _ := second.Lookup(filepath.Join(dir, "a.txt")); e.Name == gone.Name || files.get(e.Name) == nil {
+		t.Errorf("a.txt entry = %+v, want a new upload", e)
+	}
+	kept, _ := first.Lookup(filepath.Join(dir, "b.txt"))
+	if e, _ := second.Lookup(filepath.Join(dir, "b.txt")); e.Name != kept.Name || e.ExpirationTime == nil {
+		t.Errorf("b.txt entry = %+v, want reuse of %s", e, kept.Name)
+	}
+}
+
+func TestBulkUploaderCancelledResumeKeepsEntries(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b := &BulkUploader{Client: newTestClient(t, newFakeFiles()), ManifestPath: filepath.Join(t.TempDir(), "manifest.json")}
+	first, err := b.UploadDir(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.UploadDir(ctx, dir); err == nil {
+		t.Fatal("cancelled resume succeeded")
+	}
+	saved, err := b.loadManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range first.Entries {
+		if e, ok := saved.Lookup(want.Path); !ok || e.Err != "" || e.Name != want.Name {
+			t.Errorf("saved entry for %s = %+v, want %s kept", want.Path, e, want.Name)
+		}
+	}
+}
+
+func TestFilesBulkUpload(t *testing.T) {
+	_, err := FilesBulkUpload()
+	if err != nil {
+		t.Errorf("FilesBulkUpload returned an error: %v", err)
+	}
+}
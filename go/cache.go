@@ -2,8 +2,8 @@ package examples
 
 import (
 	"context"
-	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"time"
@@ -15,8 +15,9 @@ func CacheCreate() (*genai.GenerateContentResponse, error) {
 	// [START cache_create]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"), 
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -48,8 +49,7 @@ func CacheCreate() (*genai.GenerateContentResponse, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println("Cache created:")
-	fmt.Println(cache)
+	slog.Info("Cache created", "name", cache.Name, "expire_time", cache.ExpireTime)
 
 	// Use the cache for generating content.
 	response, err := client.Models.GenerateContent(
@@ -75,8 +75,9 @@ func CacheCreateFromName() (*genai.GenerateContentResponse, error) {
 	// [START cache_create_from_name]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -127,7 +128,6 @@ func CacheCreateFromName() (*genai.GenerateContentResponse, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println("Response from cache (create from name):")
 	printResponse(response)
 	// [END cache_create_from_name]
 
@@ -139,8 +139,9 @@ func CacheCreateFromChat() (*genai.GenerateContentResponse, error) {
 	// [START cache_create_from_chat]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -183,7 +184,7 @@ func CacheCreateFromChat() (*genai.GenerateContentResponse, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println("\n\nmodel: ", resp.Text())
+	printResponse(resp)
 
 	resp, err = chat.SendMessage(
 		ctx, 
@@ -194,7 +195,7 @@ func CacheCreateFromChat() (*genai.GenerateContentResponse, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println("\n\nmodel: ", resp.Text())
+	printResponse(resp)
 
 	// To cache the conversation so far, pass the chat history as the list of contents.
 	cache, err := client.Caches.Create(ctx, modelName, &genai.CreateCachedContentConfig{
@@ -222,7 +223,7 @@ func CacheCreateFromChat() (*genai.GenerateContentResponse, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println("\n\nmodel: ", resp.Text())
+	printResponse(resp)
 	// [END cache_create_from_chat]
 
 	// Clean up the cache.
@@ -236,8 +237,9 @@ func CacheDelete() error {
 	// [START cache_delete]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -275,7 +277,7 @@ func CacheDelete() error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println("Cache deleted:", cache.Name)
+	slog.Info("Cache deleted", "name", cache.Name)
 	// [END cache_delete]
 	return err
 }
@@ -284,8 +286,9 @@ func CacheGet() error {
 	// [START cache_get]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -323,8 +326,7 @@ func CacheGet() error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println("Retrieved cache:")
-	fmt.Println(cache)
+	slog.Info("Retrieved cache", "name", cache.Name, "model", cache.Model, "expire_time", cache.ExpireTime)
 	// [END cache_get]
 
 	_, err = client.Caches.Delete(ctx, cache.Name, &genai.DeleteCachedContentConfig{})
@@ -335,8 +337,9 @@ func CacheList() error {
 	// [START cache_list]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -370,28 +373,13 @@ func CacheList() error {
 		log.Fatal(err)
 	}
 
-	// List caches using the List method with a page size of 2.
-	page, err := client.Caches.List(ctx, &genai.ListCachedContentsConfig{PageSize: 2})
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	pageIndex := 1
-	for {
-		fmt.Printf("Listing caches (page %d):\n", pageIndex)
-		for _, item := range page.Items {
-			fmt.Println("   ", item.Name)
-		}
-		if page.NextPageToken == "" {
-			break
-		}
-		page, err = page.Next(ctx)
-		if err == genai.ErrPageDone {
-			break
-		} else if err != nil {
-			return err
+	// List the caches of this model. Caches.All fetches the next page as
+	// the loop reaches it.
+	for item, err := range Filter(client.Caches.All(ctx), CacheForModel(modelName)) {
+		if err != nil {
+			log.Fatal(err)
 		}
-		pageIndex++
+		slog.Info("Cache", "name", item.Name, "model", item.Model)
 	}
 	// [END cache_list]
 
@@ -403,8 +391,9 @@ func CacheUpdate() error {
 	// [START cache_update]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -444,8 +433,7 @@ func CacheUpdate() error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println("After update:")
-	fmt.Println(cache)
+	slog.Info("Cache updated", "name", cache.Name, "expire_time", cache.ExpireTime)
 
 	// Alternatively, update expire_time directly.
 	expire := time.Now().Add(15 * time.Minute).UTC()
@@ -455,8 +443,7 @@ func CacheUpdate() error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println("After expire_time update:")
-	fmt.Println(cache)
+	slog.Info("Cache expire_time updated", "name", cache.Name, "expire_time", cache.ExpireTime)
 	// [END cache_update]
 
 	_, err = client.Caches.Delete(ctx, cache.Name, &genai.DeleteCachedContentConfig{})
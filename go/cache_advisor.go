@@ -0,0 +1,175 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// CacheAdvice compares caching some contents with resending them on every
+// query. Costs are in USD and cover only the repeated contents; the rest of
+// each prompt and the output cost the same either way.
+type CacheAdvice struct {
+	Model   string
+	Tokens  int
+	Queries int
+	TTL     time.Duration
+	// WithoutCache is the cost of sending the contents with every query.
+	WithoutCache float64
+	// WithCache is the cost of creating the cache, storing it for TTL and
+	// reading it once per query.
+	WithCache float64
+	// BreakEvenQueries is the fewest queries within TTL for which caching is
+	// cheaper, or 0 if it never is.
+	BreakEvenQueries int
+	// Cacheable is false when the contents are below the model's minimum
+	// cacheable size.
+	Cacheable bool
+	Warnings  []string
+}
+
+// Savings is how much cheaper caching is; negative when it costs more.
+func (a *CacheAdvice) Savings() float64 {
+	return a.WithoutCache - a.WithCache
+}
+
+// Worthwhile reports whether the contents can be cached and caching is cheaper.
+func (a *CacheAdvice) Worthwhile() bool {
+	return a.Cacheable && a.Savings() > 0
+}
+
+// CacheAdvisor estimates whether context caching pays off.
+type CacheAdvisor struct {
+	Client *genai.Client
+	Rates  RateTable
+}
+
+// NewCacheAdvisor returns an advisor that prices with rates.
+func NewCacheAdvisor(client *genai.Client, rates RateTable) *CacheAdvisor {
+	return &CacheAdvisor{Client: client, Rates: rates}
+}
+
+// Advise counts the tokens in config's contents and system instruction and
+// prices queries requests against them over ttl. Creating the cache is
+// counted as one request at the full input price.
+func (a *CacheAdvisor) Advise(ctx context.Context, model string, config *genai.CreateCachedContentConfig, queries int, ttl time.Duration) (*CacheAdvice, error) {
+	if queries < 1 || ttl <= 0 {
+		return nil, errors.New("cache advisor: queries and ttl must be positive")
+	}
+	rate, ok := a.Rates.Lookup(model)
+	if !ok {
+		return nil, fmt.Errorf("cache advisor: no rate for %s", model)
+	}
+	// The Gemini API does not count system instructions separately, so count
+	// it as one more content.
+	contents := config.Contents
+	if config.SystemInstruction != nil {
+		contents = append([]*genai.Content{config.SystemInstruction}, contents...)
+	}
+	resp, err := a.Client.Models.CountTokens(ctx, model, contents, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cache advisor: count tokens: %w", err)
+	}
+	return adviseCache(model, rate, int(resp.TotalTokens), queries, ttl), nil
+}
+
+func adviseCache(model string, rate ModelRate, tokens, queries int, ttl time.Duration) *CacheAdvice {
+	full := rate.Cost(tokens, 0, 0)
+	read := rate.Cost(tokens, tokens, 0)
+	storage := float64(tokens) / 1e6 * rate.CacheStoragePerMillionHour * ttl.Hours()
+	advice := &CacheAdvice{
+		Model:        model,
+		Tokens:       tokens,
+		Queries:      queries,
+		TTL:          ttl,
+		WithoutCache: float64(queries) * full,
+		WithCache:    full + storage + float64(queries)*read,
+		Cacheable:    tokens >= rate.MinCacheTokens,
+	}
+	if full > read {
+		advice.BreakEvenQueries = int((full+storage)/(full-read)) + 1
+	}
+	if !advice.Cacheable {
+		advice.Warnings = append(advice.Warnings, fmt.Sprintf(
+			"contents are %d tokens; %s caches at least %d", tokens, model, rate.MinCacheTokens))
+	}
+	if rate.CacheStoragePerMillionHour == 0 {
+		advice.Warnings = append(advice.Warnings, "no cache storage price for "+model)
+	}
+	if advice.Cacheable && advice.BreakEvenQueries > queries {
+		advice.Warnings = append(advice.Warnings, fmt.Sprintf(
+			"caching pays off after %d queries, %d expected", advice.BreakEvenQueries, queries))
+	}
+	return advice
+}
+
+func TokensCacheAdvisor() (*CacheAdvice, error) {
+	// [START tokens_cache_advisor]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := client.Files.UploadFromPath(
+		ctx,
+		filepath.Join(getMedia(), "a11.txt"),
+		&genai.UploadFileConfig{MIMEType: "text/plain"},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	config := &genai.CreateCachedContentConfig{
+		Contents: []*genai.Content{
+			genai.NewContentFromParts([]*genai.Part{
+				genai.NewPartFromURI(file.URI, file.MIMEType),
+			}, "user"),
+		},
+		SystemInstruction: genai.NewContentFromText(
+			"You are an expert analyzing transcripts.", "user",
+		),
+		TTL: time.Hour,
+	}
+
+	// Ask whether 20 questions in the next hour justify caching the transcript.
+	advisor := NewCacheAdvisor(client, ExampleRates)
+	advice, err := advisor.Advise(ctx, "gemini-1.5-flash-001", config, 20, config.TTL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.Info("Cache advice",
+		"tokens", advice.Tokens,
+		"queries", advice.Queries,
+		"ttl", advice.TTL,
+		"without_cache_usd", advice.WithoutCache,
+		"with_cache_usd", advice.WithCache,
+		"break_even_queries", advice.BreakEvenQueries,
+	)
+	for _, w := range advice.Warnings {
+		slog.Warn(w)
+	}
+
+	if advice.Worthwhile() {
+		cache, err := client.Caches.Create(ctx, "gemini-1.5-flash-001", config)
+		if err != nil {
+			log.Fatal(err)
+		}
+		slog.Info("Cache created", "name", cache.Name)
+		if _, err := client.Caches.Delete(ctx, cache.Name, nil); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// [END tokens_cache_advisor]
+	return advice, err
+}
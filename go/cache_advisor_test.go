@@ -0,0 +1,53 @@
+package examples
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestAdviseCache(t *testing.T) {
+	rate, _ := ExampleRates.Lookup("gemini-2.0-flash")
+	a := adviseCache("gemini-2.0-flash", rate, 100000, 20, time.Hour)
+	if !almostEqual(a.WithoutCache, 0.2) || !almostEqual(a.WithCache, 0.16) {
+		t.Errorf("without = %v, with = %v; want 0.2 and 0.16", a.WithoutCache, a.WithCache)
+	}
+	if a.BreakEvenQueries != 15 || !a.Worthwhile() || len(a.Warnings) != 0 {
+		t.Errorf("advice = %+v, want worthwhile from 15 queries", a)
+	}
+
+	a = adviseCache("gemini-2.0-flash", rate, 100000, 10, time.Hour)
+	if a.Worthwhile() || len(a.Warnings) != 1 || !strings.Contains(a.Warnings[0], "after 15 queries") {
+		t.Errorf("advice = %+v, want not worthwhile with a break-even warning", a)
+	}
+}
+
+func TestCacheAdvisorBelowMinimum(t *testing.T) {
+	var generated atomic.Int32
+	advisor := NewCacheAdvisor(preflightServer(t, 1000, &generated), ExampleRates)
+	a, err := advisor.Advise(context.Background(), "gemini-1.5-flash-001", &genai.CreateCachedContentConfig{
+		Contents:          genai.Text("short"),
+		SystemInstruction: genai.NewContentFromText("Be brief.", "user"),
+	}, 100, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Cacheable || a.Worthwhile() || len(a.Warnings) != 1 || !strings.Contains(a.Warnings[0], "at least 32768") {
+		t.Errorf("advice = %+v, want below-minimum warning", a)
+	}
+
+	if _, err := advisor.Advise(context.Background(), "gemma-3", &genai.CreateCachedContentConfig{}, 1, time.Hour); err == nil {
+		t.Error("unpriced model was accepted")
+	}
+}
+
+func TestTokensCacheAdvisor(t *testing.T) {
+	_, err := TokensCacheAdvisor()
+	if err != nil {
+		t.Errorf("TokensCacheAdvisor returned an error: %v", err)
+	}
+}
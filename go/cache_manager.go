@@ -0,0 +1,476 @@
+package examples
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// cacheKeyPrefix starts the display name of every cache CacheManager creates;
+// the rest of the name is the content key.
+const cacheKeyPrefix = "ck-"
+
+// CacheKey derives a stable key for caching config's contents on model. Two
+// configs with the same model, system instruction, tools and contents get
+// the same key.
+func CacheKey(model string, config *genai.CreateCachedContentConfig) string {
+	h := sha256.New()
+	h.Write([]byte(strings.TrimPrefix(model, "models/")))
+	hashJSON := func(v any) {
+		b, _ := json.Marshal(v)
+		sum := sha256.Sum256(b)
+		h.Write([]byte{0})
+		h.Write(sum[:])
+	}
+	hashJSON(config.SystemInstruction)
+	hashJSON(config.Tools)
+	hashJSON(config.ToolConfig)
+	for _, c := range config.Contents {
+		hashJSON(c)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// CacheManager shares cachedContents entries between callers that cache the
+// same content. Caches are found by key, extended while leased and deleted
+// once idle.
+type CacheManager struct {
+	Client *genai.Client
+	// TTL is set when a cache is created and each time it is extended. Zero
+	// means 10 minutes.
+	TTL time.Duration
+	// IdleTimeout is how long an unleased cache is kept before Sweep deletes
+	// it. Zero means 5 minutes.
+	IdleTimeout time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*managedCache
+	now     func() time.Time
+}
+
+type managedCache struct {
+	name     string
+	expire   time.Time
+	lastUsed time.Time
+	leases   int
+	// busy is set while a goroutine creates, extends or deletes the cache
+	// without holding the manager's lock, and is closed when it is done.
+	// Only that goroutine changes name and expire.
+	busy chan struct{}
+}
+
+// NewCacheManager returns a CacheManager with default TTL and idle timeout.
+func NewCacheManager(client *genai.Client) *CacheManager {
+	return &CacheManager{Client: client}
+}
+
+func (m *CacheManager) ttl() time.Duration {
+	if m.TTL > 0 {
+		return m.TTL
+	}
+	return 10 * time.Minute
+}
+
+func (m *CacheManager) idleTimeout() time.Duration {
+	if m.IdleTimeout > 0 {
+		return m.IdleTimeout
+	}
+	return 5 * time.Minute
+}
+
+func (m *CacheManager) clock() time.Time {
+	if m.now != nil {
+		return m.now()
+	}
+	return time.Now()
+}
+
+// CacheLease keeps a managed cache alive until it is released.
+type CacheLease struct {
+	// Name is the cachedContents resource to set as CachedContent.
+	Name string
+	// Key is the content key of the cache.
+	Key string
+	// Reused reports whether an existing cache was used instead of creating
+	// one.
+	Reused bool
+
+	m    *CacheManager
+	once sync.Once
+}
+
+// Release ends the lease. The cache stays available for reuse until it has
+// been idle for IdleTimeout.
+func (l *CacheLease) Release() {
+	l.once.Do(func() {
+		l.m.mu.Lock()
+		defer l.m.mu.Unlock()
+		if e, ok := l.m.entries[l.Key]; ok {
+			e.leases--
+			e.lastUsed = l.m.clock()
+		}
+	})
+}
+
+// Drop releases lease and deletes its cache at once unless another lease
+// still holds it.
+func (m *CacheManager) Drop(ctx context.Context, lease *CacheLease) error {
+	lease.Release()
+	e, err := m.claim(ctx, lease.Key)
+	if err != nil || e == nil {
+		return err
+	}
+	m.mu.Lock()
+	idle := e.leases == 0 && e.name != ""
+	m.mu.Unlock()
+
+	var derr error
+	if idle {
+		derr = m.delete(ctx, e.name)
+	}
+	m.mu.Lock()
+	m.releaseLocked(lease.Key, e, idle && derr == nil)
+	m.mu.Unlock()
+	return derr
+}
+
+// Acquire returns a lease on a cache of config for model. A cache already
+// created for the same key, by this manager or an earlier run, is reused and
+// extended; otherwise one is created. config.DisplayName and config.TTL are
+// set by the manager. Calls for the same key wait for each other; calls for
+// different keys run concurrently.
+func (m *CacheManager) Acquire(ctx context.Context, model string, config *genai.CreateCachedContentConfig) (*CacheLease, error) {
+	key := CacheKey(model, config)
+	for {
+		m.mu.Lock()
+		if m.entries == nil {
+			m.entries = make(map[string]*managedCache)
+		}
+		if m.entries[key] == nil {
+			m.entries[key] = &managedCache{}
+		}
+		m.mu.Unlock()
+
+		e, err := m.claim(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if e == nil {
+			// Swept while we waited for it.
+			continue
+		}
+		name, expire, reused, err := m.resolve(ctx, model, key, config, e.name, e.expire)
+
+		m.mu.Lock()
+		if err != nil {
+			m.releaseLocked(key, e, name == "")
+			m.mu.Unlock()
+			return nil, err
+		}
+		e.name, e.expire = name, expire
+		e.leases++
+		e.lastUsed = m.clock()
+		m.releaseLocked(key, e, false)
+		m.mu.Unlock()
+		return &CacheLease{Name: name, Key: key, Reused: reused, m: m}, nil
+	}
+}
+
+// claim waits until nothing else is working on the cache for key and marks
+// it busy. It returns nil if the manager no longer holds the cache.
+func (m *CacheManager) claim(ctx context.Context, key string) (*managedCache, error) {
+	for {
+		m.mu.Lock()
+		e := m.entries[key]
+		if e == nil || e.busy == nil {
+			if e != nil {
+				e.busy = make(chan struct{})
+			}
+			m.mu.Unlock()
+			return e, nil
+		}
+		busy := e.busy
+		m.mu.Unlock()
+		select {
+		case <-busy:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("cache manager: %w", ctx.Err())
+		}
+	}
+}
+
+// releaseLocked clears the busy mark set by claim and, if remove is set,
+// forgets the cache.
+func (m *CacheManager) releaseLocked(key string, e *managedCache, remove bool) {
+	close(e.busy)
+	e.busy = nil
+	if remove && m.entries[key] == e {
+		delete(m.entries, key)
+	}
+}
+
+// resolve returns a live cache for key: the known one named name, one found
+// remotely or a new one. On error name is empty unless the known cache may
+// still exist.
+func (m *CacheManager) resolve(ctx context.Context, model, key string, config *genai.CreateCachedContentConfig, name string, expire time.Time) (string, time.Time, bool, error) {
+	if name != "" {
+		exp, err := m.extend(ctx, name, expire)
+		if err == nil {
+			return name, exp, true, nil
+		}
+		if !errors.Is(err, errCacheGone) {
+			return name, expire, false, err
+		}
+	}
+	found, err := m.findRemote(ctx, model, key)
+	if err != nil {
+		return "", time.Time{}, false, err
+	}
+	if found != nil {
+		exp, err := m.extend(ctx, found.Name, found.ExpireTime)
+		if err == nil {
+			return found.Name, exp, true, nil
+		}
+		if !errors.Is(err, errCacheGone) {
+			return "", time.Time{}, false, err
+		}
+	}
+	cfg := *config
+	cfg.DisplayName = cacheKeyPrefix + key
+	cfg.TTL = m.ttl()
+	c, err := m.Client.Caches.Create(ctx, model, &cfg)
+	if err != nil {
+		return "", time.Time{}, false, fmt.Errorf("cache manager: create: %w", err)
+	}
+	return c.Name, c.ExpireTime, false, nil
+}
+
+var errCacheGone = errors.New("cache no longer exists")
+
+// extend pushes the expiry of the cache named name out by TTL once less than
+// half of it remains, and returns the new expiry.
+func (m *CacheManager) extend(ctx context.Context, name string, expire time.Time) (time.Time, error) {
+	if expire.Sub(m.clock()) > m.ttl()/2 {
+		return expire, nil
+	}
+	c, err := m.Client.Caches.Update(ctx, name, &genai.UpdateCachedContentConfig{TTL: m.ttl()})
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return expire, errCacheGone
+	}
+	if err != nil {
+		return expire, fmt.Errorf("cache manager: extend %s: %w", name, err)
+	}
+	return c.ExpireTime, nil
+}
+
+// findRemote pages through the project's caches for an unexpired one with key.
+func (m *CacheManager) findRemote(ctx context.Context, model, key string) (*genai.CachedContent, error) {
+	model = strings.TrimPrefix(model, "models/")
+	for c, err := range m.Client.Caches.All(ctx) {
+		if err != nil {
+			return nil, fmt.Errorf("cache manager: list caches: %w", err)
+		}
+		if c.DisplayName == cacheKeyPrefix+key && strings.TrimPrefix(c.Model, "models/") == model && c.ExpireTime.After(m.clock()) {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+// Sweep extends leased caches that are close to expiring and deletes caches
+// that have been idle for longer than IdleTimeout.
+func (m *CacheManager) Sweep(ctx context.Context) error {
+	m.mu.Lock()
+	var keys []string
+	for key, e := range m.entries {
+		if e.busy == nil && m.sweepable(e) {
+			keys = append(keys, key)
+		}
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	for _, key := range keys {
+		e, err := m.claim(ctx, key)
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if e == nil {
+			continue
+		}
+		m.mu.Lock()
+		leased, due := e.leases > 0, m.sweepable(e)
+		m.mu.Unlock()
+
+		var remove bool
+		switch {
+		case !due:
+		case leased:
+			exp, err := m.extend(ctx, e.name, e.expire)
+			remove = errors.Is(err, errCacheGone)
+			if err != nil && !remove {
+				errs = append(errs, err)
+			}
+			m.mu.Lock()
+			e.expire = exp
+			m.mu.Unlock()
+		default:
+			err := m.delete(ctx, e.name)
+			remove = err == nil
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+		m.mu.Lock()
+		m.releaseLocked(key, e, remove)
+		m.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// sweepable reports whether Sweep has work to do on e.
+func (m *CacheManager) sweepable(e *managedCache) bool {
+	if e.name == "" {
+		return false
+	}
+	if e.leases > 0 {
+		return e.expire.Sub(m.clock()) <= m.ttl()/2
+	}
+	return m.clock().Sub(e.lastUsed) >= m.idleTimeout()
+}
+
+// Maintain calls Sweep every interval until ctx is done. Sweep errors are
+// logged and do not stop the loop.
+func (m *CacheManager) Maintain(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := m.Sweep(ctx); err != nil {
+				slog.Warn("Cache sweep failed", "err", err)
+			}
+		}
+	}
+}
+
+// Close deletes every cache the manager holds, leased or not.
+func (m *CacheManager) Close(ctx context.Context) error {
+	m.mu.Lock()
+	keys := make([]string, 0, len(m.entries))
+	for key := range m.entries {
+		keys = append(keys, key)
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	for _, key := range keys {
+		e, err := m.claim(ctx, key)
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if e == nil {
+			continue
+		}
+		var derr error
+		if e.name != "" {
+			derr = m.delete(ctx, e.name)
+		}
+		if derr != nil {
+			errs = append(errs, derr)
+		}
+		m.mu.Lock()
+		m.releaseLocked(key, e, derr == nil)
+		m.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// delete deletes the cache named name. A cache that is already gone is not
+// an error.
+func (m *CacheManager) delete(ctx context.Context, name string) error {
+	_, err := m.Client.Caches.Delete(ctx, name, nil)
+	var apiErr genai.APIError
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound) {
+		return fmt.Errorf("cache manager: delete %s: %w", name, err)
+	}
+	return nil
+}
+
+func CacheManagerReuse() (*genai.GenerateContentResponse, error) {
+	// [START cache_manager_reuse]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	modelName := "gemini-1.5-flash-001"
+	document, err := NewFileUploader(client, DefaultUploadIndexPath()).UploadFromPath(
+		ctx,
+		filepath.Join(getMedia(), "a11.txt"),
+		&genai.UploadFileConfig{MIMEType: "text/plain"},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	config := &genai.CreateCachedContentConfig{
+		Contents: []*genai.Content{
+			genai.NewContentFromParts([]*genai.Part{
+				genai.NewPartFromURI(document.URI, document.MIMEType),
+			}, "user"),
+		},
+		SystemInstruction: genai.NewContentFromText(
+			"You are an expert analyzing transcripts.", "user",
+		),
+	}
+
+	manager := NewCacheManager(client)
+	manager.IdleTimeout = 2 * time.Minute
+	maintainCtx, stopMaintain := context.WithCancel(ctx)
+	defer stopMaintain()
+	go manager.Maintain(maintainCtx, 30*time.Second)
+
+	var response *genai.GenerateContentResponse
+	for _, question := range []string{
+		"Please summarize this transcript.",
+		"Find a lighthearted moment from this transcript.",
+	} {
+		// Both questions share one cache, found again by its content key.
+		lease, err := manager.Acquire(ctx, modelName, config)
+		if err != nil {
+			log.Fatal(err)
+		}
+		slog.Info("Using cache", "name", lease.Name, "reused", lease.Reused)
+		response, err = client.Models.GenerateContent(ctx, modelName, genai.Text(question),
+			&genai.GenerateContentConfig{CachedContent: lease.Name})
+		lease.Release()
+		if err != nil {
+			log.Fatal(err)
+		}
+		printResponse(response)
+	}
+	// [END cache_manager_reuse]
+
+	return response, manager.Close(ctx)
+}
@@ -0,0 +1,207 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func transcriptCacheConfig(text string) *genai.CreateCachedContentConfig {
+	return &genai.CreateCachedContentConfig{
+		Contents:          genai.Text(text),
+		SystemInstruction: genai.NewContentFromText("You are an expert analyzing transcripts.", "user"),
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	a := CacheKey("gemini-1.5-flash-001", transcriptCacheConfig("one"))
+	if b := CacheKey("models/gemini-1.5-flash-001", transcriptCacheConfig("one")); a != b {
+		t.Errorf("key depends on the models/ prefix: %s != %s", a, b)
+	}
+	if b := CacheKey("gemini-1.5-flash-001", transcriptCacheConfig("two")); a == b {
+		t.Error("different contents share a key")
+	}
+	if b := CacheKey("gemini-1.5-pro-001", transcriptCacheConfig("one")); a == b {
+		t.Error("different models share a key")
+	}
+	if len(a) != 32 {
+		t.Errorf("key %q is not 32 hex digits", a)
+	}
+}
+
+func TestCacheManagerReuses(t *testing.T) {
+	caches := newFakeCaches()
+	client := newTestClient(t, caches)
+	ctx := context.Background()
+	m := NewCacheManager(client)
+
+	first, err := m.Acquire(ctx, "gemini-1.5-flash-001", transcriptCacheConfig("one"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := m.Acquire(ctx, "gemini-1.5-flash-001", transcriptCacheConfig("one"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Reused || !second.Reused || first.Name != second.Name {
+		t.Errorf("leases %+v and %+v, want the second to reuse the first", first, second)
+	}
+	if _, err := m.Acquire(ctx, "gemini-1.5-flash-001", transcriptCacheConfig("two")); err != nil {
+		t.Fatal(err)
+	}
+	if creates, _ := caches.counts(); creates != 2 {
+		t.Errorf("created %d caches, want 2", creates)
+	}
+
+	// A new manager, as in a later run, finds the cache by its display name.
+	again, err := NewCacheManager(client).Acquire(ctx, "gemini-1.5-flash-001", transcriptCacheConfig("one"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !again.Reused || again.Name != first.Name {
+		t.Errorf("new manager got %+v, want reuse of %s", again, first.Name)
+	}
+	if creates, _ := caches.counts(); creates != 2 {
+		t.Errorf("created %d caches, want 2", creates)
+	}
+}
+
+func TestCacheManagerConcurrentAcquire(t *testing.T) {
+	caches := newFakeCaches()
+	slowKey := CacheKey("gemini-1.5-flash-001", transcriptCacheConfig("slow"))
+	unblock := make(chan struct{})
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Hold the create of the slow cache until unblocked.
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if r.Method == http.MethodPost && bytes.Contains(body, []byte(cacheKeyPrefix+slowKey)) {
+			<-unblock
+		}
+		caches.ServeHTTP(w, r)
+	}))
+	m := NewCacheManager(client)
+	ctx := context.Background()
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := m.Acquire(ctx, "gemini-1.5-flash-001", transcriptCacheConfig("slow"))
+		slow <- err
+	}()
+
+	// Other keys are not held up by the slow create, and concurrent calls
+	// for one key share a single cache.
+	var wg sync.WaitGroup
+	names := make([]string, 4)
+	for i := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lease, err := m.Acquire(ctx, "gemini-1.5-flash-001", transcriptCacheConfig("fast"))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			names[i] = lease.Name
+		}()
+	}
+	wg.Wait()
+	shared := !slices.ContainsFunc(names, func(n string) bool { return n != names[0] })
+	if creates, _ := caches.counts(); creates != 1 || !shared {
+		t.Errorf("created %d caches with names %v, want one shared cache", creates, names)
+	}
+
+	close(unblock)
+	if err := <-slow; err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCacheManagerExtendsAndDeletes(t *testing.T) {
+	caches := newFakeCaches()
+	ctx := context.Background()
+	clock := &fakeClock{t: time.Now()}
+	m := &CacheManager{Client: newTestClient(t, caches), TTL: 10 * time.Minute, IdleTimeout: time.Minute, now: clock.Now}
+
+	lease, err := m.Acquire(ctx, "gemini-1.5-flash-001", transcriptCacheConfig("one"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(4 * time.Minute)
+	if err := m.Sweep(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, updates := caches.counts(); updates != 0 {
+		t.Errorf("extended after 4 of 10 minutes")
+	}
+	// Less than half the TTL remains, so the leased cache is extended.
+	clock.Advance(2 * time.Minute)
+	if err := m.Sweep(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, updates := caches.counts(); updates != 1 {
+		t.Errorf("got %d updates, want 1", updates)
+	}
+
+	lease.Release()
+	lease.Release()
+	clock.Advance(30 * time.Second)
+	m.Sweep(ctx)
+	if !slices.Contains(caches.names(), lease.Name) {
+		t.Fatal("cache deleted before the idle timeout")
+	}
+	clock.Advance(time.Minute)
+	if err := m.Sweep(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(caches.names(), lease.Name) {
+		t.Error("idle cache was not deleted")
+	}
+}
+
+func TestCacheManagerRecreatesExpired(t *testing.T) {
+	caches := newFakeCaches()
+	ctx := context.Background()
+	clock := &fakeClock{t: time.Now()}
+	client := newTestClient(t, caches)
+	m := &CacheManager{Client: client, now: clock.Now}
+
+	lease, err := m.Acquire(ctx, "gemini-1.5-flash-001", transcriptCacheConfig("one"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lease.Release()
+	// The cache disappears behind the manager's back.
+	client.Caches.Delete(ctx, lease.Name, nil)
+	clock.Advance(9 * time.Minute)
+
+	again, err := m.Acquire(ctx, "gemini-1.5-flash-001", transcriptCacheConfig("one"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Reused || again.Name == lease.Name {
+		t.Errorf("got %+v, want a new cache", again)
+	}
+	if err := m.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(caches.names()); n != 0 {
+		t.Errorf("%d caches left after Close", n)
+	}
+}
+
+func TestCacheManagerReuse(t *testing.T) {
+	_, err := CacheManagerReuse()
+	if err != nil {
+		t.Errorf("CacheManagerReuse returned an error: %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// CachedChat is a chat session that moves older turns into a context cache
+// once the uncached part of the conversation grows past Threshold tokens,
+// so long conversations are not resent in full on every turn.
+type CachedChat struct {
+	Client *genai.Client
+	Model  string
+	// Config applies to every turn. Its system instruction and tools are
+	// stored in the cache once there is one.
+	Config *genai.GenerateContentConfig
+	// Threshold is the number of uncached tokens, prompt plus reply, that
+	// triggers a new cache. Zero means the model's MinCacheTokens in
+	// ExampleRates, or 32768 if it is not listed.
+	Threshold int
+	// KeepRecent is the number of latest contents left out of the cache. Zero
+	// means 2, the last question and answer.
+	KeepRecent int
+	// TTL is how long the cache outlives the last turn that used it. Zero
+	// means one hour. It is ignored when Caches is set.
+	TTL time.Duration
+	// Caches creates, extends and deletes the chat's caches. Nil means a
+	// manager of the chat's own.
+	Caches *CacheManager
+
+	mu          sync.Mutex
+	cached      []*genai.Content
+	recent      []*genai.Content
+	cacheConfig *genai.CreateCachedContentConfig
+	lease       *CacheLease
+	manager     *CacheManager
+}
+
+// NewCachedChat starts a chat on model with optional history.
+func NewCachedChat(client *genai.Client, model string, config *genai.GenerateContentConfig, history []*genai.Content) *CachedChat {
+	return &CachedChat{Client: client, Model: model, Config: config, recent: slices.Clone(history)}
+}
+
+func (c *CachedChat) threshold() int {
+	if c.Threshold > 0 {
+		return c.Threshold
+	}
+	if r, ok := ExampleRates.Lookup(c.Model); ok && r.MinCacheTokens > 0 {
+		return r.MinCacheTokens
+	}
+	return 32768
+}
+
+func (c *CachedChat) managerLocked() *CacheManager {
+	if c.Caches != nil {
+		return c.Caches
+	}
+	if c.manager == nil {
+		ttl := c.TTL
+		if ttl == 0 {
+			ttl = time.Hour
+		}
+		c.manager = &CacheManager{Client: c.Client, TTL: ttl}
+	}
+	return c.manager
+}
+
+// History returns the whole conversation, cached and uncached.
+func (c *CachedChat) History() []*genai.Content {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Concat(c.cached, c.recent)
+}
+
+// CacheName returns the cache currently holding the older turns, or "" if
+// the conversation has not been cached yet.
+func (c *CachedChat) CacheName() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lease == nil {
+		return ""
+	}
+	return c.lease.Name
+}
+
+// SendMessage sends parts as the next user turn.
+func (c *CachedChat) SendMessage(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	input := &genai.Content{Role: "user"}
+	for i := range parts {
+		input.Parts = append(input.Parts, &parts[i])
+	}
+	var cfg genai.GenerateContentConfig
+	if c.Config != nil {
+		cfg = *c.Config
+	}
+	if c.cacheConfig != nil {
+		// Leasing the cache again on every turn extends it, or recreates it
+		// if the chat sat idle until it expired.
+		if err := c.renewLocked(ctx); err != nil {
+			return nil, err
+		}
+		cfg.CachedContent = c.lease.Name
+		cfg.SystemInstruction, cfg.Tools, cfg.ToolConfig = nil, nil, nil
+	}
+	resp, err := c.Client.Models.GenerateContent(ctx, c.Model, append(slices.Clone(c.recent), input), &cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return resp, nil
+	}
+	c.recent = append(c.recent, input, resp.Candidates[0].Content)
+
+	if u := resp.UsageMetadata; u != nil {
+		uncached := int(u.PromptTokenCount) - int(u.CachedContentTokenCount) + int(u.CandidatesTokenCount)
+		if uncached > c.threshold() {
+			if err := c.rollLocked(ctx); err != nil {
+				return resp, err
+			}
+		}
+	}
+	return resp, nil
+}
+
+// renewLocked replaces the chat's lease with a fresh one on the same contents.
+func (c *CachedChat) renewLocked(ctx context.Context) error {
+	lease, err := c.managerLocked().Acquire(ctx, c.Model, c.cacheConfig)
+	if err != nil {
+		return fmt.Errorf("cached chat: %w", err)
+	}
+	c.lease.Release()
+	c.lease = lease
+	return nil
+}
+
+// rollLocked replaces the cache with one that also holds every turn but the
+// last KeepRecent, then drops the old cache.
+func (c *CachedChat) rollLocked(ctx context.Context) error {
+	keep := c.KeepRecent
+	if keep == 0 {
+		keep = 2
+	}
+	// Split before a user turn so the uncached history still starts with one.
+	split := len(c.recent) - keep
+	for split > 0 && c.recent[split].Role != "user" {
+		split--
+	}
+	if split <= 0 {
+		return nil
+	}
+	contents := slices.Concat(c.cached, c.recent[:split])
+	cfg := &genai.CreateCachedContentConfig{Contents: contents}
+	if c.Config != nil {
+		cfg.SystemInstruction, cfg.Tools, cfg.ToolConfig = c.Config.SystemInstruction, c.Config.Tools, c.Config.ToolConfig
+	}
+	m := c.managerLocked()
+	lease, err := m.Acquire(ctx, c.Model, cfg)
+	if err != nil {
+		return fmt.Errorf("cached chat: %w", err)
+	}
+	old := c.lease
+	c.lease, c.cacheConfig, c.cached, c.recent = lease, cfg, contents, slices.Clone(c.recent[split:])
+	if old != nil {
+		if err := m.Drop(ctx, old); err != nil {
+			return fmt.Errorf("cached chat: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close deletes the chat's cache. The chat can still be used; the next
+// promotion creates a new cache.
+func (c *CachedChat) Close(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lease == nil {
+		return nil
+	}
+	if err := c.managerLocked().Drop(ctx, c.lease); err != nil {
+		return fmt.Errorf("cached chat: %w", err)
+	}
+	// Without the cache the older turns have to be sent again.
+	c.recent = slices.Concat(c.cached, c.recent)
+	c.cached, c.cacheConfig, c.lease = nil, nil, nil
+	return nil
+}
+
+func CacheChatAutoPromote() (*genai.GenerateContentResponse, error) {
+	// [START cache_chat_auto_promote]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	modelName := "gemini-1.5-flash-001"
+	document, err := client.Files.UploadFromPath(
+		ctx,
+		filepath.Join(getMedia(), "a11.txt"),
+		&genai.UploadFileConfig{MIMEType: "text/plain"},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The transcript pushes the conversation past the threshold. After the
+	// second reply the first exchange moves into a cache, and later turns
+	// read it from there instead of resending it.
+	chat := NewCachedChat(client, modelName, &genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText("You are an expert analyzing transcripts.", "user"),
+	}, nil)
+	var resp *genai.GenerateContentResponse
+	for _, message := range [][]genai.Part{
+		{
+			{Text: "Hi, could you summarize this transcript?"},
+			{FileData: &genai.FileData{FileURI: document.URI, MIMEType: document.MIMEType}},
+		},
+		{{Text: "Okay, could you tell me more about the trans-lunar injection"}},
+		{{Text: "I didn't understand that last part, could you explain it in simpler language?"}},
+	} {
+		resp, err = chat.SendMessage(ctx, message...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printResponse(resp)
+		slog.Info("Chat cache", "name", chat.CacheName())
+	}
+	// [END cache_chat_auto_promote]
+
+	return resp, chat.Close(ctx)
+}
@@ -0,0 +1,146 @@
+package examples
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// chatServer fakes caches and generateContent. Every content costs 1000
+// tokens, cached or not, and every reply another 1000.
+type chatServer struct {
+	caches *fakeCaches
+
+	mu       sync.Mutex
+	requests []map[string]json.RawMessage
+}
+
+func (s *chatServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, ":generateContent") {
+		s.caches.ServeHTTP(w, r)
+		return
+	}
+	var req map[string]json.RawMessage
+	json.NewDecoder(r.Body).Decode(&req)
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+
+	var contents []*genai.Content
+	json.Unmarshal(req["contents"], &contents)
+	var cacheName string
+	json.Unmarshal(req["cachedContent"], &cacheName)
+	cached := 1000 * len(s.caches.cachedContents(cacheName))
+	resp := textResponse("ok")
+	resp["usageMetadata"] = map[string]any{
+		"promptTokenCount":        1000*len(contents) + cached,
+		"cachedContentTokenCount": cached,
+		"candidatesTokenCount":    1000,
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func TestCachedChatRollsForward(t *testing.T) {
+	srv := &chatServer{caches: newFakeCaches()}
+	ctx := context.Background()
+	chat := NewCachedChat(newTestClient(t, srv), "gemini-1.5-flash-001", &genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText("Be brief.", "user"),
+	}, nil)
+	chat.Threshold = 3500
+
+	send := func(text string) {
+		t.Helper()
+		if _, err := chat.SendMessage(ctx, genai.Part{Text: text}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	send("one") // 1000 prompt + 1000 reply: below the threshold
+	if chat.CacheName() != "" {
+		t.Fatal("cached below the threshold")
+	}
+	send("two") // 3000 + 1000: the first exchange is cached
+	first := chat.CacheName()
+	if first == "" || len(srv.caches.cachedContents(first)) != 2 {
+		t.Fatalf("cache %q holds %d contents, want 2", first, len(srv.caches.cachedContents(first)))
+	}
+	send("three") // 3000 uncached + 1000: rolls forward
+	second := chat.CacheName()
+	if names := srv.caches.names(); !slices.Equal(names, []string{second}) || second == first {
+		t.Fatalf("caches = %v, want only the new cache %s", names, second)
+	}
+	if n := len(srv.caches.cachedContents(second)); n != 4 {
+		t.Errorf("rolled cache holds %d contents, want 4", n)
+	}
+	if n := len(chat.History()); n != 6 {
+		t.Errorf("history has %d contents, want 6", n)
+	}
+
+	last := srv.requests[2]
+	var contents []*genai.Content
+	json.Unmarshal(last["contents"], &contents)
+	if _, ok := last["systemInstruction"]; ok || len(contents) != 3 || contents[0].Parts[0].Text != "two" {
+		t.Errorf("third request sent %d contents and systemInstruction %s", len(contents), last["systemInstruction"])
+	}
+
+	if err := chat.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.caches.names()) != 0 || len(chat.History()) != 6 {
+		t.Errorf("Close left caches %v and %d contents", srv.caches.names(), len(chat.History()))
+	}
+}
+
+func TestCachedChatOutlivesTTL(t *testing.T) {
+	srv := &chatServer{caches: newFakeCaches()}
+	ctx := context.Background()
+	client := newTestClient(t, srv)
+	clock := &fakeClock{t: time.Now()}
+	chat := NewCachedChat(client, "gemini-1.5-flash-001", nil, nil)
+	chat.Threshold = 3500
+	chat.Caches = &CacheManager{Client: client, TTL: time.Hour, now: clock.Now}
+
+	send := func(text string) {
+		t.Helper()
+		if _, err := chat.SendMessage(ctx, genai.Part{Text: text}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	send("one")
+	send("two") // caches the first exchange
+
+	// Past half the TTL, the next turn extends the cache before using it.
+	clock.Advance(40 * time.Minute)
+	send("three") // also rolls forward
+	if _, updates := srv.caches.counts(); updates != 1 {
+		t.Errorf("after 40 minutes: %d updates, want 1", updates)
+	}
+
+	// Idle past the TTL, the cache has expired and is created again.
+	expired := chat.CacheName()
+	clock.Advance(2 * time.Hour)
+	if _, err := client.Caches.Delete(ctx, expired, nil); err != nil {
+		t.Fatal(err)
+	}
+	send("four")
+	name := chat.CacheName()
+	if name == "" || name == expired || len(srv.caches.cachedContents(name)) == 0 {
+		t.Errorf("after expiry the chat uses cache %q, caches = %v", name, srv.caches.names())
+	}
+	if req := srv.requests[len(srv.requests)-1]; string(req["cachedContent"]) == `"`+expired+`"` {
+		t.Errorf("last request still used the expired cache %s", expired)
+	}
+}
+
+func TestCacheChatAutoPromote(t *testing.T) {
+	_, err := CacheChatAutoPromote()
+	if err != nil {
+		t.Errorf("CacheChatAutoPromote returned an error: %v", err)
+	}
+}
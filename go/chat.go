@@ -2,9 +2,8 @@ package examples
 
 import (
 	"context"
-	"fmt"
-	"strings"
 	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 
@@ -15,8 +14,9 @@ func Chat() error {
 	// [START chat]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -37,13 +37,13 @@ func Chat() error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println(firstResp.Text())
+	printResponse(firstResp)
 
 	secondResp, err := chat.SendMessage(ctx, genai.Part{Text: "How many paws are in my house?"})
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println(secondResp.Text())
+	printResponse(secondResp)
 	// [END chat]
 
 	return nil
@@ -53,8 +53,9 @@ func ChatStreaming() error {
 	// [START chat_streaming]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -73,19 +74,21 @@ func ChatStreaming() error {
 		if err != nil {
 			log.Fatal(err)
 		}
-		fmt.Println(chunk.Text())
-		fmt.Println(strings.Repeat("_", 64))
+		printResponse(chunk)
 	}
 
 	for chunk, err := range chat.SendMessageStream(ctx, genai.Part{Text: "How many paws are in my house?"}) {
 		if err != nil {
 			log.Fatal(err)
 		}
-		fmt.Println(chunk.Text())
-		fmt.Println(strings.Repeat("_", 64))
+		printResponse(chunk)
 	}
 
-	fmt.Println(chat.History(false))
+	for _, content := range chat.History(false) {
+		for _, part := range content.Parts {
+			slog.Info("History", "role", content.Role, "text", part.Text)
+		}
+	}
 	// [END chat_streaming]
 
 	return nil
@@ -95,8 +98,9 @@ func ChatStreamingWithImages() error {
 	// [START chat_streaming_with_images]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -112,8 +116,7 @@ func ChatStreamingWithImages() error {
 		if err != nil {
 			log.Fatal(err)
 		}
-		fmt.Println(chunk.Text())
-		fmt.Println(strings.Repeat("_", 64))
+		printResponse(chunk)
 	}
 
 	image, err := client.Files.UploadFromPath(
@@ -141,8 +144,7 @@ func ChatStreamingWithImages() error {
 		if err != nil {
 			log.Fatal(err)
 		}
-		fmt.Println(chunk.Text())
-		fmt.Println(strings.Repeat("_", 64))
+		printResponse(chunk)
 	}
 	// [END chat_streaming_with_images]
 
@@ -2,9 +2,9 @@ package examples
 
 import (
 	"context"
-	"fmt"
-	"os"
 	"log"
+	"log/slog"
+	"os"
 
 	"google.golang.org/genai"
 )
@@ -13,8 +13,9 @@ func CodeExecutionBasic() (*genai.GenerateContentResponse, error) {
 	// [START code_execution_basic]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -35,15 +36,11 @@ func CodeExecutionBasic() (*genai.GenerateContentResponse, error) {
 
 	// Print the response.
 	printResponse(response)
-
-	fmt.Println("--------------------------------------------------------------------------------")
-	fmt.Println(response.Text())
 	// [END code_execution_basic]
 
 	// [START code_execution_basic_return]
-	// Expected output:
+	// Expected response text:
 
-	// --------------------------------------------------------------------------------
 	// ```python
 	// def is_prime(n):
 	// 	if n <= 1:
@@ -77,8 +74,9 @@ func CodeExecutionRequestOverride() (*genai.GenerateContentResponse, error) {
 	// [START code_execution_request_override]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -103,16 +101,14 @@ Generate and run code for the calculation, and make sure you get all 50.`,
 
 	// Print the response.
 	printResponse(response)
-
-	fmt.Println("--------------------------------------------------------------------------------")
-	
-	fmt.Println(response.ExecutableCode())
-	fmt.Println(response.CodeExecutionResult())
+	slog.Info("Code execution",
+		"code", response.ExecutableCode(),
+		"result", response.CodeExecutionResult(),
+	)
 	// [END code_execution_request_override]
 
 	// [START code_execution_request_override_return]
-	// Expected output:
-	// --------------------------------------------------------------------------------
+	// Expected code and result:
 	// def is_prime(n):
 	// 	if n <= 1:
 	// 		return False
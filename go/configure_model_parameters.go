@@ -12,8 +12,9 @@ func ConfigureModelParameters() (*genai.GenerateContentResponse, error) {
 	// [START configure_model_parameters]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
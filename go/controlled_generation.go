@@ -13,8 +13,9 @@ func JsonControlledGeneration() (*genai.GenerateContentResponse, error) {
 	// [START json_controlled_generation]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"), 
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -58,8 +59,9 @@ func JsonNoSchema() (*genai.GenerateContentResponse, error) {
 	// [START json_no_schema]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"), 
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -81,8 +83,9 @@ func JsonEnum() (*genai.GenerateContentResponse, error) {
 	// [START json_enum]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"), 
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -150,8 +153,9 @@ func EnumInJson() (*genai.GenerateContentResponse, error) {
 	// [START enum_in_json]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"), 
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -190,8 +194,9 @@ func JsonEnumRaw() (*genai.GenerateContentResponse, error) {
 	// [START json_enum_raw]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"), 
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -240,8 +245,9 @@ func XEnum() (*genai.GenerateContentResponse, error) {
 	// [START x_enum]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -308,8 +314,9 @@ func XEnumRaw() (*genai.GenerateContentResponse, error) {
 	// [START x_enum_raw]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -2,12 +2,11 @@ package examples
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"time"
 	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 
 	"google.golang.org/genai"
 )
@@ -16,8 +15,9 @@ func TokensContextWindow() error {
 	// [START tokens_context_window]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -27,8 +27,10 @@ func TokensContextWindow() error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Printf("input_token_limit=%d\n", modelInfo.InputTokenLimit)
-	fmt.Printf("output_token_limit=%d\n", modelInfo.OutputTokenLimit)
+	slog.Info("Token limits",
+		"input_token_limit", modelInfo.InputTokenLimit,
+		"output_token_limit", modelInfo.OutputTokenLimit,
+	)
 	// [END tokens_context_window]
 	return err
 }
@@ -37,8 +39,9 @@ func TokensTextOnly() error {
 	// [START tokens_text_only]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -53,17 +56,26 @@ func TokensTextOnly() error {
 	if err != nil {
 		return err
 	}
-	fmt.Println("total_tokens:", countResp.TotalTokens)
+	slog.Info("Token count", "total_tokens", countResp.TotalTokens)
 
 	response, err := client.Models.GenerateContent(ctx, "gemini-2.0-flash", contents, nil)
 	if err != nil {
 		log.Fatal(err)
 	}
-	usageMetadata, err := json.MarshalIndent(response.UsageMetadata, "", "  ")
-	if err != nil {
-		log.Fatal(err)
+	printResponse(response)
+
+	// Record the usage and its cost in a ledger instead of discarding it.
+	ledger := NewLedger(ExampleRates)
+	entry := ledger.Record(UsageTags{
+		User:    "example-user",
+		Feature: "tokens_text_only",
+		Model:   "gemini-2.0-flash",
+	}, response.UsageMetadata)
+	slog.Info("Usage", "prompt_tokens", entry.PromptTokens, "output_tokens", entry.CandidatesTokens, "cost_usd", entry.Cost)
+	for _, r := range ledger.DailyRollups() {
+		slog.Info("Daily usage", "day", r.Day, "user", r.User, "feature", r.Feature, "model", r.Model,
+			"requests", r.Requests, "prompt_tokens", r.PromptTokens, "output_tokens", r.CandidatesTokens, "cost_usd", r.Cost)
 	}
-	fmt.Println(string(usageMetadata))
 	// [END tokens_text_only]
 	return err
 }
@@ -72,8 +84,9 @@ func TokensChat() error {
 	// [START tokens_chat]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -93,7 +106,7 @@ func TokensChat() error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println(firstTokenResp.TotalTokens)
+	slog.Info("History token count", "total_tokens", firstTokenResp.TotalTokens)
 
 	resp, err := chat.SendMessage(ctx, genai.Part{
 		Text: "In one sentence, explain how a computer works to a young child."},
@@ -101,7 +114,16 @@ func TokensChat() error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Printf("%#v\n", resp.UsageMetadata)
+	printResponse(resp)
+
+	// Record the usage of the chat turn in a ledger.
+	ledger := NewLedger(ExampleRates)
+	entry := ledger.Record(UsageTags{
+		User:    "Bob",
+		Feature: "tokens_chat",
+		Model:   "gemini-2.0-flash",
+	}, resp.UsageMetadata)
+	slog.Info("Usage", "prompt_tokens", entry.PromptTokens, "output_tokens", entry.CandidatesTokens, "cost_usd", entry.Cost)
 
 	// Append an extra user message and recount.
 	extra := genai.NewContentFromText("What is the meaning of life?", "user")
@@ -112,7 +134,12 @@ func TokensChat() error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println(secondTokenResp.TotalTokens)
+	slog.Info("History token count with extra message", "total_tokens", secondTokenResp.TotalTokens)
+
+	for _, r := range ledger.DailyRollups() {
+		slog.Info("Daily usage", "day", r.Day, "user", r.User, "feature", r.Feature, "model", r.Model,
+			"requests", r.Requests, "prompt_tokens", r.PromptTokens, "output_tokens", r.CandidatesTokens, "cost_usd", r.Cost)
+	}
 	// [END tokens_chat]
 
 	return nil
@@ -122,8 +149,9 @@ func TokensMultimodalImageFileApi() error {
 	// [START tokens_multimodal_image_file_api]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -151,17 +179,13 @@ func TokensMultimodalImageFileApi() error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println("Multimodal image token count:", tokenResp.TotalTokens)
+	slog.Info("Multimodal image token count", "total_tokens", tokenResp.TotalTokens)
 
 	response, err := client.Models.GenerateContent(ctx, "gemini-2.0-flash", contents, nil)
 	if err != nil {
 		log.Fatal(err)
 	}
-	usageMetadata, err := json.MarshalIndent(response.UsageMetadata, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(usageMetadata))
+	printResponse(response)
 	// [END tokens_multimodal_image_file_api]
 	return err
 }
@@ -170,8 +194,9 @@ func TokensMultimodalVideoAudioFileApi() error {
 	// [START tokens_multimodal_video_audio_file_api]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -188,16 +213,15 @@ func TokensMultimodalVideoAudioFileApi() error {
 		log.Fatal(err)
 	}
 
-	// Poll until the video file is completely processed (state becomes ACTIVE).
-	for file.State == genai.FileStateUnspecified || file.State != genai.FileStateActive {
-		fmt.Println("Processing video...")
-		fmt.Println("File state:", file.State)
-		time.Sleep(5 * time.Second)
-
-		file, err = client.Files.Get(ctx, file.Name, nil)
-		if err != nil {
-			log.Fatal(err)
-		}
+	// Poll with backoff until the video file is completely processed (state
+	// becomes ACTIVE), giving up after five minutes.
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	file, err = WaitForFileActive(waitCtx, client, file.Name, &WaitOptions{
+		Progress: func(f *genai.File) { slog.Info("File state", "name", f.Name, "state", f.State) },
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	parts := []*genai.Part{
@@ -212,16 +236,12 @@ func TokensMultimodalVideoAudioFileApi() error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println("Multimodal video/audio token count:", tokenResp.TotalTokens)
+	slog.Info("Multimodal video/audio token count", "total_tokens", tokenResp.TotalTokens)
 	response, err := client.Models.GenerateContent(ctx, "gemini-2.0-flash", contents, nil)
 	if err != nil {
 		log.Fatal(err)
 	}
-	usageMetadata, err := json.MarshalIndent(response.UsageMetadata, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(usageMetadata))
+	printResponse(response)
 	// [END tokens_multimodal_video_audio_file_api]
 	return err
 }
@@ -230,8 +250,9 @@ func TokensMultimodalPdfFileApi() error {
 	// [START tokens_multimodal_pdf_file_api]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -259,16 +280,12 @@ func TokensMultimodalPdfFileApi() error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Printf("Multimodal PDF token count: %d\n", tokenResp.TotalTokens)
+	slog.Info("Multimodal PDF token count", "total_tokens", tokenResp.TotalTokens)
 	response, err := client.Models.GenerateContent(ctx, "gemini-2.0-flash", contents, nil)
 	if err != nil {
 		log.Fatal(err)
 	}
-	usageMetadata, err := json.MarshalIndent(response.UsageMetadata, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(string(usageMetadata))
+	printResponse(response)
 	// [END tokens_multimodal_pdf_file_api]
 	return err
 }
@@ -277,8 +294,9 @@ func TokensCachedContent() error {
 	// [START tokens_cached_content]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -317,7 +335,7 @@ func TokensCachedContent() error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Printf("%d", countResp.TotalTokens)
+	slog.Info("Prompt token count", "total_tokens", countResp.TotalTokens)
 	response, err := client.Models.GenerateContent(ctx, "gemini-1.5-flash-001", []*genai.Content{
 		genai.NewContentFromText(prompt, "user"),
 	}, &genai.GenerateContentConfig{
@@ -327,12 +345,7 @@ func TokensCachedContent() error {
 		log.Fatal(err)
 	}
 
-	usageMetadata, err := json.MarshalIndent(response.UsageMetadata, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	// Returns `nil` for some reason
-	fmt.Println(string(usageMetadata))
+	printResponse(response)
 	_, err = client.Caches.Delete(ctx, cache.Name, &genai.DeleteCachedContentConfig{})
 	// [END tokens_cached_content]
 	return err
@@ -2,10 +2,9 @@ package examples
 
 import (
 	"context"
-	"fmt"
-	"os"
 	"log"
-	"encoding/json"
+	"log/slog"
+	"os"
 
 	"google.golang.org/genai"
 )
@@ -14,8 +13,9 @@ func EmbedContent() error {
 	// [START embed_content]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -34,11 +34,9 @@ func EmbedContent() error {
 		log.Fatal(err)
 	}
 
-	embeddings, err := json.MarshalIndent(result.Embeddings, "", "  ")
-	if err != nil {
-		log.Fatal(err)
+	for i, embedding := range result.Embeddings {
+		slog.Info("Embedding", "index", i, "values", embedding.Values)
 	}
-	fmt.Println(string(embeddings))
 	// [END embed_content]
 	return err
 }
@@ -47,8 +45,9 @@ func BatchEmbedContents() error {
 	// [START batch_embed_contents]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -68,11 +67,9 @@ func BatchEmbedContents() error {
 		log.Fatal(err)
 	}
 	
-	embeddings, err := json.MarshalIndent(result.Embeddings, "", "  ")
-	if err != nil {
-		log.Fatal(err)
+	for i, embedding := range result.Embeddings {
+		slog.Info("Embedding", "index", i, "values", embedding.Values)
 	}
-	fmt.Println(string(embeddings))
 	// [END batch_embed_contents]
 	return err
 }
@@ -0,0 +1,360 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// newTestClient returns a client that talks to a local fake of the Gemini API
+// served by h, so helpers can be tested without an API key.
+func newTestClient(t *testing.T, h http.Handler) *genai.Client {
+	t.Helper()
+	return newTestClientWithConfig(t, h, &genai.ClientConfig{})
+}
+
+// newTestClientWithConfig is like newTestClient but lets the caller set extra
+// client options such as HTTPClient.
+func newTestClientWithConfig(t *testing.T, h http.Handler, cc *genai.ClientConfig) *genai.Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	cc.APIKey = "test-key"
+	cc.Backend = genai.BackendGeminiAPI
+	cc.HTTPOptions.BaseURL = srv.URL
+	client, err := genai.NewClient(context.Background(), cc)
+	if err != nil {
+		t.Fatalf("genai.NewClient: %v", err)
+	}
+	return client
+}
+
+// writeJSON writes v as a JSON response body with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeAPIError writes an error body in the shape the Gemini API uses.
+func writeAPIError(w http.ResponseWriter, code int, status, message string) {
+	writeJSON(w, code, map[string]any{
+		"error": map[string]any{
+			"code":    code,
+			"message": message,
+			"status":  status,
+		},
+	})
+}
+
+// textResponse builds a minimal GenerateContent response body.
+func textResponse(text string) map[string]any {
+	return map[string]any{
+		"candidates": []any{
+			map[string]any{
+				"content": map[string]any{
+					"role":  "model",
+					"parts": []any{map[string]any{"text": text}},
+				},
+				"finishReason": "STOP",
+			},
+		},
+	}
+}
+
+// fakeFiles is an in-memory fake of the Files API: resumable uploads, get,
+// paged list and delete.
+type fakeFiles struct {
+	// PageSize is the number of files per list page. Zero means 2.
+	PageSize int
+	// ProcessingPolls is how many Get calls a new file stays PROCESSING for.
+	ProcessingPolls int
+	// Fail marks new files whose display name has this prefix as FAILED.
+	Fail string
+
+	mu       sync.Mutex
+	files    map[string]*genai.File
+	order    []string
+	polls    map[string]int
+	sessions map[string]*bytes.Buffer
+	meta     map[string]*genai.File
+	uploads  int
+	next     int
+}
+
+func newFakeFiles() *fakeFiles {
+	return &fakeFiles{
+		files:    make(map[string]*genai.File),
+		polls:    make(map[string]int),
+		sessions: make(map[string]*bytes.Buffer),
+		meta:     make(map[string]*genai.File),
+	}
+}
+
+// add stores f as if it had been uploaded earlier.
+func (s *fakeFiles) add(f *genai.File) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[f.Name] = f
+	s.order = append(s.order, f.Name)
+}
+
+// uploadCount returns the number of completed uploads.
+func (s *fakeFiles) uploadCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.uploads
+}
+
+func (s *fakeFiles) get(name string) *genai.File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.files[name]
+}
+
+func (s *fakeFiles) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := "/" + strings.TrimLeft(r.URL.Path, "/")
+	switch {
+	case path == "/upload/v1beta/files":
+		var body struct {
+			File *genai.File `json:"file"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.File == nil {
+			body.File = &genai.File{}
+		}
+		s.next++
+		id := strconv.Itoa(s.next)
+		s.sessions[id] = &bytes.Buffer{}
+		s.meta[id] = body.File
+		w.Header().Set("X-Goog-Upload-URL", "http://"+r.Host+"/upload-session/"+id)
+		w.WriteHeader(http.StatusOK)
+	case strings.HasPrefix(path, "/upload-session/"):
+		id := strings.TrimPrefix(path, "/upload-session/")
+		buf, ok := s.sessions[id]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "NOT_FOUND", "no such upload")
+			return
+		}
+		io.Copy(buf, r.Body)
+		if !strings.Contains(r.Header.Get("X-Goog-Upload-Command"), "finalize") {
+			w.Header().Set("X-Goog-Upload-Status", "active")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		f := s.finalize(id, buf.Bytes())
+		w.Header().Set("X-Goog-Upload-Status", "final")
+		writeJSON(w, http.StatusOK, map[string]any{"file": f})
+	case path == "/v1beta/files" && r.Method == http.MethodGet:
+		s.list(w, r)
+	case strings.HasPrefix(path, "/v1beta/files/"):
+		name := strings.TrimPrefix(path, "/v1beta/")
+		f, ok := s.files[name]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "NOT_FOUND", name+" not found")
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(s.files, name)
+			s.order = slices.DeleteFunc(s.order, func(n string) bool { return n == name })
+			writeJSON(w, http.StatusOK, map[string]any{})
+			return
+		}
+		if f.State == genai.FileStateProcessing {
+			s.polls[name]++
+			if s.polls[name] > s.ProcessingPolls {
+				f.State = genai.FileStateActive
+			}
+		}
+		writeJSON(w, http.StatusOK, f)
+	default:
+		writeAPIError(w, http.StatusNotFound, "NOT_FOUND", "unexpected "+r.Method+" "+path)
+	}
+}
+
+func (s *fakeFiles) finalize(id string, data []byte) *genai.File {
+	meta := s.meta[id]
+	delete(s.sessions, id)
+	delete(s.meta, id)
+	s.uploads++
+
+	sum := sha256.Sum256(data)
+	size := int64(len(data))
+	now := time.Now().UTC()
+	f := &genai.File{
+		Name:           fmt.Sprintf("files/upload-%s", id),
+		DisplayName:    meta.DisplayName,
+		MIMEType:       meta.MIMEType,
+		SizeBytes:      &size,
+		CreateTime:     now,
+		ExpirationTime: now.Add(48 * time.Hour),
+		Sha256Hash:     base64.StdEncoding.EncodeToString(sum[:]),
+		State:          genai.FileStateActive,
+	}
+	f.URI = "https://generativelanguage.googleapis.com/v1beta/" + f.Name
+	if s.ProcessingPolls > 0 {
+		f.State = genai.FileStateProcessing
+	}
+	if s.Fail != "" && strings.HasPrefix(f.DisplayName, s.Fail) {
+		f.State = genai.FileStateFailed
+		f.Error = &genai.FileStatus{Message: "unsupported video codec"}
+	}
+	s.files[f.Name] = f
+	s.order = append(s.order, f.Name)
+	return f
+}
+
+func (s *fakeFiles) list(w http.ResponseWriter, r *http.Request) {
+	size := s.PageSize
+	if size == 0 {
+		size = 2
+	}
+	start, _ := strconv.Atoi(r.URL.Query().Get("pageToken"))
+	end := min(start+size, len(s.order))
+	page := []*genai.File{}
+	for _, name := range s.order[start:end] {
+		page = append(page, s.files[name])
+	}
+	resp := map[string]any{"files": page}
+	if end < len(s.order) {
+		resp["nextPageToken"] = strconv.Itoa(end)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// fakeCaches is an in-memory fake of the cachedContents API: create, get,
+// paged list, TTL update and delete.
+type fakeCaches struct {
+	// PageSize is the number of caches per list page. Zero means 2.
+	PageSize int
+
+	mu       sync.Mutex
+	caches   map[string]*genai.CachedContent
+	contents map[string][]*genai.Content
+	order    []string
+	next     int
+	creates  int
+	updates  int
+}
+
+func newFakeCaches() *fakeCaches {
+	return &fakeCaches{
+		caches:   make(map[string]*genai.CachedContent),
+		contents: make(map[string][]*genai.Content),
+	}
+}
+
+// counts returns the number of create and update calls received.
+func (s *fakeCaches) counts() (creates, updates int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.creates, s.updates
+}
+
+// names returns the names of the live caches in creation order.
+func (s *fakeCaches) names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.order)
+}
+
+// cachedContents returns the contents a cache was created with.
+func (s *fakeCaches) cachedContents(name string) []*genai.Content {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.contents[name]
+}
+
+func (s *fakeCaches) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := "/" + strings.TrimLeft(r.URL.Path, "/")
+	var body struct {
+		Model             string           `json:"model"`
+		DisplayName       string           `json:"displayName"`
+		Contents          []*genai.Content `json:"contents"`
+		SystemInstruction *genai.Content   `json:"systemInstruction"`
+		TTL               string           `json:"ttl"`
+	}
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&body)
+	}
+	ttl, _ := time.ParseDuration(body.TTL)
+	if ttl == 0 {
+		ttl = time.Hour
+	}
+	now := time.Now().UTC()
+
+	switch {
+	case path == "/v1beta/cachedContents" && r.Method == http.MethodPost:
+		s.next++
+		s.creates++
+		c := &genai.CachedContent{
+			Name:        fmt.Sprintf("cachedContents/cache-%d", s.next),
+			DisplayName: body.DisplayName,
+			Model:       body.Model,
+			CreateTime:  now,
+			UpdateTime:  now,
+			ExpireTime:  now.Add(ttl),
+		}
+		s.caches[c.Name] = c
+		s.contents[c.Name] = body.Contents
+		s.order = append(s.order, c.Name)
+		writeJSON(w, http.StatusOK, c)
+	case path == "/v1beta/cachedContents" && r.Method == http.MethodGet:
+		size := s.PageSize
+		if size == 0 {
+			size = 2
+		}
+		start, _ := strconv.Atoi(r.URL.Query().Get("pageToken"))
+		end := min(start+size, len(s.order))
+		page := []*genai.CachedContent{}
+		for _, name := range s.order[start:end] {
+			page = append(page, s.caches[name])
+		}
+		resp := map[string]any{"cachedContents": page}
+		if end < len(s.order) {
+			resp["nextPageToken"] = strconv.Itoa(end)
+		}
+		writeJSON(w, http.StatusOK, resp)
+	case strings.HasPrefix(path, "/v1beta/cachedContents/"):
+		name := strings.TrimPrefix(path, "/v1beta/")
+		c, ok := s.caches[name]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "NOT_FOUND", name+" not found")
+			return
+		}
+		switch r.Method {
+		case http.MethodDelete:
+			delete(s.caches, name)
+			delete(s.contents, name)
+			s.order = slices.DeleteFunc(s.order, func(n string) bool { return n == name })
+			writeJSON(w, http.StatusOK, map[string]any{})
+			return
+		case http.MethodPatch:
+			s.updates++
+			c.UpdateTime = now
+			c.ExpireTime = now.Add(ttl)
+		}
+		writeJSON(w, http.StatusOK, c)
+	default:
+		writeAPIError(w, http.StatusNotFound, "NOT_FOUND", "unexpected "+r.Method+" "+path)
+	}
+}
@@ -0,0 +1,113 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"google.golang.org/genai"
+)
+
+// FallbackResponse is a GenerateContent response together with the model
+// that actually served it.
+type FallbackResponse struct {
+	*genai.GenerateContentResponse
+	// Model is the entry of the chain that returned the response.
+	Model string
+	// Skipped lists the models that were tried first and why they were passed over.
+	Skipped []ModelAttempt
+}
+
+// ModelAttempt records a model that could not serve a request.
+type ModelAttempt struct {
+	Model string
+	Err   error
+}
+
+// GenerateContentWithFallback sends the request to each model in order and
+// returns the first successful response. It only moves on to the next model
+// when the error means the model is gone (404), out of quota (429) or
+// overloaded (503); any other error is returned straight away.
+func GenerateContentWithFallback(
+	ctx context.Context,
+	client *genai.Client,
+	models []string,
+	contents []*genai.Content,
+	config *genai.GenerateContentConfig,
+) (*FallbackResponse, error) {
+	if len(models) == 0 {
+		return nil, errors.New("fallback: no models given")
+	}
+	var skipped []ModelAttempt
+	for _, model := range models {
+		resp, err := client.Models.GenerateContent(ctx, model, contents, config)
+		if err == nil {
+			return &FallbackResponse{GenerateContentResponse: resp, Model: model, Skipped: skipped}, nil
+		}
+		if !shouldFallback(err) {
+			return nil, fmt.Errorf("fallback: %s: %w", model, err)
+		}
+		skipped = append(skipped, ModelAttempt{Model: model, Err: err})
+	}
+
+	errs := make([]error, len(skipped))
+	for i, a := range skipped {
+		errs[i] = fmt.Errorf("%s: %w", a.Model, a.Err)
+	}
+	return nil, fmt.Errorf("fallback: all models failed: %w", errors.Join(errs...))
+}
+
+// shouldFallback reports whether err means the request may succeed on a
+// different model.
+func shouldFallback(err error) bool {
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.Code {
+	case http.StatusNotFound, http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	}
+	switch apiErr.Status {
+	case "NOT_FOUND", "RESOURCE_EXHAUSTED", "UNAVAILABLE":
+		return true
+	}
+	return false
+}
+
+func TextGenWithModelFallback() (*FallbackResponse, error) {
+	// [START text_gen_with_model_fallback]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Experimental models are retired over time, so keep a stable model at the end.
+	models := []string{"gemini-2.5-pro-exp-03-25", "gemini-2.0-flash"}
+	response, err := GenerateContentWithFallback(
+		ctx,
+		client,
+		models,
+		genai.Text("Write a story about a magic backpack."),
+		nil,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, s := range response.Skipped {
+		slog.Warn("Skipped model", "model", s.Model, "err", s.Err)
+	}
+	slog.Info("Served by", "model", response.Model)
+	printResponse(response.GenerateContentResponse)
+	// [END text_gen_with_model_fallback]
+	return response, err
+}
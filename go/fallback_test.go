@@ -0,0 +1,99 @@
+package examples
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestGenerateContentWithFallback(t *testing.T) {
+	var called []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "retired-model"):
+			called = append(called, "retired-model")
+			writeAPIError(w, http.StatusNotFound, "NOT_FOUND", "model not found")
+		case strings.Contains(r.URL.Path, "busy-model"):
+			called = append(called, "busy-model")
+			writeAPIError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "The model is overloaded.")
+		default:
+			called = append(called, "stable-model")
+			writeJSON(w, http.StatusOK, textResponse("hello"))
+		}
+	}))
+
+	resp, err := GenerateContentWithFallback(
+		context.Background(),
+		client,
+		[]string{"retired-model", "busy-model", "stable-model"},
+		genai.Text("hi"),
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("GenerateContentWithFallback returned an error: %v", err)
+	}
+	if resp.Model != "stable-model" {
+		t.Errorf("Model = %q, want %q", resp.Model, "stable-model")
+	}
+	if len(resp.Skipped) != 2 {
+		t.Errorf("len(Skipped) = %d, want 2", len(resp.Skipped))
+	}
+	if resp.Text() != "hello" {
+		t.Errorf("Text() = %q, want %q", resp.Text(), "hello")
+	}
+	if len(called) != 3 {
+		t.Errorf("server saw %v, want three calls", called)
+	}
+}
+
+func TestGenerateContentWithFallbackStopsOnOtherErrors(t *testing.T) {
+	calls := 0
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		writeAPIError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "bad request")
+	}))
+
+	_, err := GenerateContentWithFallback(
+		context.Background(),
+		client,
+		[]string{"first-model", "second-model"},
+		genai.Text("hi"),
+		nil,
+	)
+	if err == nil {
+		t.Fatal("expected an error for INVALID_ARGUMENT")
+	}
+	if calls != 1 {
+		t.Errorf("server called %d times, want 1", calls)
+	}
+}
+
+func TestGenerateContentWithFallbackAllFail(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusTooManyRequests, "RESOURCE_EXHAUSTED", "quota exceeded")
+	}))
+
+	_, err := GenerateContentWithFallback(
+		context.Background(),
+		client,
+		[]string{"a", "b"},
+		genai.Text("hi"),
+		nil,
+	)
+	if err == nil {
+		t.Fatal("expected an error when every model fails")
+	}
+	if !strings.Contains(err.Error(), "a:") || !strings.Contains(err.Error(), "b:") {
+		t.Errorf("error %q does not mention every model", err)
+	}
+}
+
+func TestTextGenWithModelFallback(t *testing.T) {
+	_, err := TextGenWithModelFallback()
+	if err != nil {
+		t.Errorf("TextGenWithModelFallback returned an error: %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// FileInventory is every file stored in the project.
+type FileInventory struct {
+	Files      []*genai.File
+	TotalBytes int64
+	ByState    map[genai.FileState]int
+}
+
+// ListAllFiles pages through all of the project's files.
+func ListAllFiles(ctx context.Context, client *genai.Client) (*FileInventory, error) {
+	inv := &FileInventory{ByState: make(map[genai.FileState]int)}
+	for f, err := range client.Files.All(ctx) {
+		if err != nil {
+			return nil, fmt.Errorf("list files: %w", err)
+		}
+		inv.Files = append(inv.Files, f)
+		inv.ByState[f.State]++
+		if f.SizeBytes != nil {
+			inv.TotalBytes += *f.SizeBytes
+		}
+	}
+	return inv, nil
+}
+
+// WriteTable writes one row per file followed by the totals.
+func (inv *FileInventory) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tDISPLAY NAME\tSIZE\tSTATE\tCREATED\tEXPIRES")
+	for _, f := range inv.Files {
+		var size int64
+		if f.SizeBytes != nil {
+			size = *f.SizeBytes
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			f.Name, f.DisplayName, formatBytes(size), f.State, formatTime(f.CreateTime), formatTime(f.ExpirationTime))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d files, %s total\n", len(inv.Files), formatBytes(inv.TotalBytes))
+	return err
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.DateTime)
+}
+
+// GCRule selects files to delete. Every field that is set must match; a
+// rule with no fields set is rejected.
+type GCRule struct {
+	// OlderThan matches files created at least this long ago.
+	OlderThan time.Duration
+	// NamePrefix matches the resource name, such as "files/tmp-".
+	NamePrefix string
+	// DisplayNamePrefix matches a label stored in the display name, such as
+	// "sha256-" for files uploaded by FileUploader.
+	DisplayNamePrefix string
+}
+
+func (r GCRule) empty() bool {
+	return r == GCRule{}
+}
+
+func (r GCRule) matches(f *genai.File, now time.Time) bool {
+	if r.OlderThan > 0 && (f.CreateTime.IsZero() || now.Sub(f.CreateTime) < r.OlderThan) {
+		return false
+	}
+	if r.NamePrefix != "" && !strings.HasPrefix(f.Name, r.NamePrefix) {
+		return false
+	}
+	if r.DisplayNamePrefix != "" && !strings.HasPrefix(f.DisplayName, r.DisplayNamePrefix) {
+		return false
+	}
+	return true
+}
+
+// GCResult lists what a collection deleted, or would delete in a dry run.
+type GCResult struct {
+	Deleted    []*genai.File
+	Kept       int
+	FreedBytes int64
+}
+
+// CollectFiles deletes every file that matches at least one rule. With dryRun
+// set it only reports what it would delete. Deletion failures are joined in
+// the returned error; the result still lists the files that were deleted.
+func CollectFiles(ctx context.Context, client *genai.Client, rules []GCRule, dryRun bool) (*GCResult, error) {
+	return collectFiles(ctx, client, rules, dryRun, time.Now())
+}
+
+func collectFiles(ctx context.Context, client *genai.Client, rules []GCRule, dryRun bool, now time.Time) (*GCResult, error) {
+	if len(rules) == 0 {
+		return nil, errors.New("gc: no rules")
+	}
+	for _, r := range rules {
+		if r.empty() {
+			return nil, errors.New("gc: a rule must set at least one field")
+		}
+	}
+	inv, err := ListAllFiles(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &GCResult{}
+	var errs []error
+	for _, f := range inv.Files {
+		match := false
+		for _, r := range rules {
+			if r.matches(f, now) {
+				match = true
+				break
+			}
+		}
+		if !match {
+			res.Kept++
+			continue
+		}
+		if !dryRun {
+			if _, err := client.Files.Delete(ctx, f.Name, nil); err != nil {
+				errs = append(errs, fmt.Errorf("gc: delete %s: %w", f.Name, err))
+				res.Kept++
+				continue
+			}
+		}
+		res.Deleted = append(res.Deleted, f)
+		if f.SizeBytes != nil {
+			res.FreedBytes += *f.SizeBytes
+		}
+	}
+	return res, errors.Join(errs...)
+}
+
+func FilesInventory() error {
+	// [START files_inventory]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	inventory, err := ListAllFiles(ctx, client)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := inventory.WriteTable(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+
+	// Show which files a cleanup would remove without deleting anything.
+	result, err := CollectFiles(ctx, client, []GCRule{
+		{OlderThan: 24 * time.Hour},
+		{DisplayNamePrefix: "sha256-", OlderThan: time.Hour},
+	}, true)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, f := range result.Deleted {
+		slog.Info("Would delete", "name", f.Name, "display_name", f.DisplayName)
+	}
+	slog.Info("Dry run", "would_free", formatBytes(result.FreedBytes), "kept", result.Kept)
+	// [END files_inventory]
+	return err
+}
@@ -0,0 +1,108 @@
+package examples
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func inventoryFiles(now time.Time) *fakeFiles {
+	files := newFakeFiles()
+	for _, f := range []struct {
+		name, display string
+		age           time.Duration
+		size          int64
+	}{
+		{"files/old", "report.pdf", 72 * time.Hour, 2048},
+		{"files/tmp-1", "scratch", time.Hour, 10},
+		{"files/recent", "sha256-abc", 10 * time.Minute, 100},
+		{"files/labelled", "sha256-def", 3 * time.Hour, 1 << 20},
+		{"files/keep", "keep.png", 2 * time.Hour, 5},
+	} {
+		size := f.size
+		files.add(&genai.File{
+			Name:        f.name,
+			DisplayName: f.display,
+			SizeBytes:   &size,
+			CreateTime:  now.Add(-f.age),
+			State:       genai.FileStateActive,
+		})
+	}
+	return files
+}
+
+func TestListAllFiles(t *testing.T) {
+	client := newTestClient(t, inventoryFiles(time.Now()))
+	inv, err := ListAllFiles(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inv.Files) != 5 || inv.TotalBytes != 2048+10+100+(1<<20)+5 || inv.ByState[genai.FileStateActive] != 5 {
+		t.Errorf("inventory = %d files, %d bytes, %v", len(inv.Files), inv.TotalBytes, inv.ByState)
+	}
+
+	var out strings.Builder
+	if err := inv.WriteTable(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "report.pdf") || !strings.Contains(out.String(), "5 files, 1.0 MiB total") {
+		t.Errorf("table:\n%s", out.String())
+	}
+}
+
+func TestCollectFiles(t *testing.T) {
+	now := time.Now()
+	files := inventoryFiles(now)
+	client := newTestClient(t, files)
+	rules := []GCRule{
+		{OlderThan: 24 * time.Hour},
+		{NamePrefix: "files/tmp-"},
+		{DisplayNamePrefix: "sha256-", OlderThan: time.Hour},
+	}
+
+	dry, err := collectFiles(context.Background(), client, rules, true, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range dry.Deleted {
+		names = append(names, f.Name)
+	}
+	if got := strings.Join(names, ","); got != "files/old,files/tmp-1,files/labelled" {
+		t.Errorf("dry run would delete %s", got)
+	}
+	if dry.Kept != 2 || dry.FreedBytes != 2048+10+(1<<20) {
+		t.Errorf("dry run kept %d, freed %d", dry.Kept, dry.FreedBytes)
+	}
+	if files.get("files/old") == nil {
+		t.Fatal("dry run deleted a file")
+	}
+
+	if _, err := collectFiles(context.Background(), client, rules, false, now); err != nil {
+		t.Fatal(err)
+	}
+	inv, err := ListAllFiles(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inv.Files) != 2 {
+		t.Errorf("%d files left, want 2", len(inv.Files))
+	}
+}
+
+func TestCollectFilesRejectsEmptyRule(t *testing.T) {
+	client := newTestClient(t, inventoryFiles(time.Now()))
+	if _, err := CollectFiles(context.Background(), client, []GCRule{{}}, false); err == nil {
+		t.Error("an empty rule was accepted")
+	}
+}
+
+func TestFilesInventory(t *testing.T) {
+	err := FilesInventory()
+	if err != nil {
+		t.Errorf("FilesInventory returned an error: %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// FileFailedError reports a file that ended in FileStateFailed.
+type FileFailedError struct {
+	Name   string
+	Status *genai.FileStatus
+}
+
+func (e *FileFailedError) Error() string {
+	msg := fmt.Sprintf("file %s failed processing", e.Name)
+	if e.Status == nil {
+		return msg
+	}
+	if e.Status.Message != "" {
+		msg += ": " + e.Status.Message
+	}
+	if e.Status.Code != nil {
+		msg += fmt.Sprintf(" (code %d)", *e.Status.Code)
+	}
+	return msg
+}
+
+// WaitOptions configures WaitForFileActive. The zero value polls after 1s,
+// doubling up to every 10s.
+type WaitOptions struct {
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+	// Progress, if set, is called with the file after every poll. When
+	// waiting on several files, calls are serialized.
+	Progress func(*genai.File)
+}
+
+func (o *WaitOptions) withDefaults() WaitOptions {
+	var w WaitOptions
+	if o != nil {
+		w = *o
+	}
+	if w.InitialInterval == 0 {
+		w.InitialInterval = time.Second
+	}
+	if w.MaxInterval == 0 {
+		w.MaxInterval = 10 * time.Second
+	}
+	if w.Multiplier == 0 {
+		w.Multiplier = 2
+	}
+	return w
+}
+
+// WaitForFileActive polls the named file until it is ACTIVE. It returns a
+// *FileFailedError if processing fails and the context's error, wrapped with
+// the last seen state, if ctx ends first.
+func WaitForFileActive(ctx context.Context, client *genai.Client, name string, opts *WaitOptions) (*genai.File, error) {
+	o := opts.withDefaults()
+	interval := o.InitialInterval
+	var last *genai.File
+	for {
+		f, err := client.Files.Get(ctx, name, nil)
+		if err != nil && last != nil && ctx.Err() != nil {
+			// ctx ended during the poll rather than between polls.
+			return last, fmt.Errorf("wait for %s (state %s): %w", name, last.State, ctx.Err())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("wait for %s: %w", name, err)
+		}
+		last = f
+		if o.Progress != nil {
+			o.Progress(f)
+		}
+		switch f.State {
+		case genai.FileStateActive:
+			return f, nil
+		case genai.FileStateFailed:
+			return f, &FileFailedError{Name: f.Name, Status: f.Error}
+		}
+
+		t := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return f, fmt.Errorf("wait for %s (state %s): %w", name, f.State, ctx.Err())
+		case <-t.C:
+		}
+		interval = min(time.Duration(float64(interval)*o.Multiplier), o.MaxInterval)
+	}
+}
+
+// WaitForFilesActive waits on every named file concurrently. The returned
+// slice matches names; the error joins the failure of each file that did not
+// become active.
+func WaitForFilesActive(ctx context.Context, client *genai.Client, names []string, opts *WaitOptions) ([]*genai.File, error) {
+	o := opts.withDefaults()
+	if progress := o.Progress; progress != nil {
+		var mu sync.Mutex
+		o.Progress = func(f *genai.File) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress(f)
+		}
+	}
+
+	files := make([]*genai.File, len(names))
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			files[i], errs[i] = WaitForFileActive(ctx, client, name, &o)
+		}()
+	}
+	wg.Wait()
+	return files, errors.Join(errs...)
+}
+
+func FilesWaitUntilActive() error {
+	// [START files_wait_until_active]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var names []string
+	for _, name := range []string{"Big_Buck_Bunny.mp4", "test.pdf"} {
+		file, err := client.Files.UploadFromPath(ctx, filepath.Join(getMedia(), name), nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		names = append(names, file.Name)
+	}
+
+	// Give up if the files are not ready within five minutes.
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	files, err := WaitForFilesActive(ctx, client, names, &WaitOptions{
+		Progress: func(f *genai.File) {
+			slog.Info("File state", "name", f.Name, "state", f.State)
+		},
+	})
+	var failed *FileFailedError
+	if errors.As(err, &failed) {
+		log.Fatalf("%s could not be processed: %v", failed.Name, failed)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, f := range files {
+		slog.Info("File ready", "name", f.Name, "uri", f.URI)
+	}
+	// [END files_wait_until_active]
+	return err
+}
@@ -0,0 +1,105 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func fastWait(progress func(*genai.File)) *WaitOptions {
+	return &WaitOptions{InitialInterval: time.Millisecond, MaxInterval: 4 * time.Millisecond, Progress: progress}
+}
+
+func TestWaitForFileActive(t *testing.T) {
+	files := newFakeFiles()
+	files.ProcessingPolls = 3
+	client := newTestClient(t, files)
+	ctx := context.Background()
+	f, err := client.Files.Upload(ctx, strings.NewReader("video"), &genai.UploadFileConfig{MIMEType: "video/mp4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var states []genai.FileState
+	f, err = WaitForFileActive(ctx, client, f.Name, fastWait(func(f *genai.File) { states = append(states, f.State) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.State != genai.FileStateActive || len(states) != 4 {
+		t.Errorf("state %s after progress %v", f.State, states)
+	}
+}
+
+func TestWaitForFileActiveFailed(t *testing.T) {
+	files := newFakeFiles()
+	files.Fail = "bad"
+	client := newTestClient(t, files)
+	ctx := context.Background()
+	f, err := client.Files.Upload(ctx, strings.NewReader("video"), &genai.UploadFileConfig{MIMEType: "video/mp4", DisplayName: "bad.mp4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = WaitForFileActive(ctx, client, f.Name, fastWait(nil))
+	var failed *FileFailedError
+	if !errors.As(err, &failed) || !strings.Contains(err.Error(), "unsupported video codec") {
+		t.Errorf("err = %v, want FileFailedError with details", err)
+	}
+}
+
+func TestWaitForFileActiveDeadline(t *testing.T) {
+	files := newFakeFiles()
+	files.ProcessingPolls = 1000
+	client := newTestClient(t, files)
+	f, err := client.Files.Upload(context.Background(), strings.NewReader("video"), &genai.UploadFileConfig{MIMEType: "video/mp4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = WaitForFileActive(ctx, client, f.Name, fastWait(nil))
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "PROCESSING") {
+		t.Errorf("err = %v, want deadline exceeded while processing", err)
+	}
+}
+
+func TestWaitForFilesActive(t *testing.T) {
+	files := newFakeFiles()
+	files.ProcessingPolls = 2
+	files.Fail = "bad"
+	client := newTestClient(t, files)
+	ctx := context.Background()
+	var names []string
+	for _, name := range []string{"a.mp4", "bad.mp4", "c.mp4"} {
+		f, err := client.Files.Upload(ctx, strings.NewReader(name), &genai.UploadFileConfig{MIMEType: "video/mp4", DisplayName: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, f.Name)
+	}
+
+	polls := 0
+	got, err := WaitForFilesActive(ctx, client, names, fastWait(func(*genai.File) { polls++ }))
+	var failed *FileFailedError
+	if !errors.As(err, &failed) || failed.Name != names[1] {
+		t.Errorf("err = %v, want failure of %s", err, names[1])
+	}
+	if got[0].State != genai.FileStateActive || got[2].State != genai.FileStateActive {
+		t.Errorf("files = %v", got)
+	}
+	if polls != 7 {
+		t.Errorf("progress called %d times, want 7", polls)
+	}
+}
+
+func TestFilesWaitUntilActive(t *testing.T) {
+	err := FilesWaitUntilActive()
+	if err != nil {
+		t.Errorf("FilesWaitUntilActive returned an error: %v", err)
+	}
+}
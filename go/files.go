@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"time"
@@ -15,8 +16,9 @@ func FilesCreateText() (*genai.GenerateContentResponse, error) {
 	// [START files_create_text]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -32,7 +34,7 @@ func FilesCreateText() (*genai.GenerateContentResponse, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Printf("myfile=%+v\n", myfile)
+	slog.Info("File uploaded", "name", myfile.Name, "uri", myfile.URI, "state", myfile.State)
 
 	parts := []*genai.Part{
 		genai.NewPartFromURI(myfile.URI, myfile.MIMEType),
@@ -48,8 +50,7 @@ func FilesCreateText() (*genai.GenerateContentResponse, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	text := response.Text()
-	fmt.Printf("result.text=%s\n", text)
+	printResponse(response)
 	// [END files_create_text]
 	return response, err
 }
@@ -58,8 +59,9 @@ func FilesCreateImage() (*genai.GenerateContentResponse, error) {
 	// [START files_create_image]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -74,7 +76,7 @@ func FilesCreateImage() (*genai.GenerateContentResponse, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Printf("myfile=%+v\n", myfile)
+	slog.Info("File uploaded", "name", myfile.Name, "uri", myfile.URI, "state", myfile.State)
 
 	parts := []*genai.Part{
 		genai.NewPartFromURI(myfile.URI, myfile.MIMEType),
@@ -90,8 +92,7 @@ func FilesCreateImage() (*genai.GenerateContentResponse, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	text := response.Text()
-	fmt.Printf("result.text=%s\n", text)
+	printResponse(response)
 	// [END files_create_image]
 	return response, err
 }
@@ -100,8 +101,9 @@ func FilesCreateAudio() (*genai.GenerateContentResponse, error) {
 	// [START files_create_audio]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -116,7 +118,7 @@ func FilesCreateAudio() (*genai.GenerateContentResponse, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Printf("myfile=%+v\n", myfile)
+	slog.Info("File uploaded", "name", myfile.Name, "uri", myfile.URI, "state", myfile.State)
 
 	parts := []*genai.Part{
 		genai.NewPartFromURI(myfile.URI, myfile.MIMEType),
@@ -131,8 +133,7 @@ func FilesCreateAudio() (*genai.GenerateContentResponse, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	text := response.Text()
-	fmt.Printf("result.text=%s\n", text)
+	printResponse(response)
 	// [END files_create_audio]
 	return response, err
 }
@@ -141,8 +142,9 @@ func FilesCreateVideo() (*genai.GenerateContentResponse, error) {
 	// [START files_create_video]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -157,18 +159,17 @@ func FilesCreateVideo() (*genai.GenerateContentResponse, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Printf("myfile=%+v\n", myfile)
+	slog.Info("File uploaded", "name", myfile.Name, "uri", myfile.URI, "state", myfile.State)
 
-	// Poll until the video file is completely processed (state becomes ACTIVE).
-	for myfile.State == genai.FileStateUnspecified || myfile.State != genai.FileStateActive {
-		fmt.Println("Processing video...")
-		fmt.Println("File state:", myfile.State)
-		time.Sleep(5 * time.Second)
-
-		myfile, err = client.Files.Get(ctx, myfile.Name, nil)
-		if err != nil {
-			log.Fatal(err)
-		}
+	// Poll with backoff until the video file is completely processed (state
+	// becomes ACTIVE), giving up after five minutes.
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	myfile, err = WaitForFileActive(waitCtx, client, myfile.Name, &WaitOptions{
+		Progress: func(f *genai.File) { slog.Info("File state", "name", f.Name, "state", f.State) },
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	parts := []*genai.Part{
@@ -184,8 +185,7 @@ func FilesCreateVideo() (*genai.GenerateContentResponse, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	text := response.Text()
-	fmt.Printf("result.text=%s\n", text)
+	printResponse(response)
 	// [END files_create_video]
 	return response, err
 }
@@ -194,8 +194,9 @@ func FilesCreatePdf() (*genai.GenerateContentResponse, error) {
 	// [START files_create_pdf]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -224,8 +225,7 @@ func FilesCreatePdf() (*genai.GenerateContentResponse, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	text := response.Text()
-	fmt.Println(text)
+	printResponse(response)
 	// [END files_create_pdf]
 	return response, err
 }
@@ -234,8 +234,9 @@ func FilesCreateFromIO() (*genai.GenerateContentResponse, error) {
 	// [START files_create_io]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -265,8 +266,7 @@ func FilesCreateFromIO() (*genai.GenerateContentResponse, error) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	text := response.Text()
-	fmt.Println(text)
+	printResponse(response)
 	// [END files_create_io]
 	return response, err
 }
@@ -275,19 +275,19 @@ func FilesList() error {
 	// [START files_list]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println("My files:")
-	page, err := client.Files.List(ctx, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, f := range page.Items {
-		fmt.Println("  ", f.Name)
+	// List the files that are ready to use in a prompt.
+	for f, err := range Filter(client.Files.All(ctx), FileInState(genai.FileStateActive)) {
+		if err != nil {
+			log.Fatal(err)
+		}
+		slog.Info("File", "name", f.Name, "mime_type", f.MIMEType)
 	}
 	// [END files_list]
 	return nil
@@ -297,8 +297,9 @@ func FilesGet() (*genai.File, error) {
 	// [START files_get]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -314,12 +315,11 @@ func FilesGet() (*genai.File, error) {
 		log.Fatal(err)
 	}
 	fileName := myfile.Name
-	fmt.Println(fileName)
 	file, err := client.Files.Get(ctx, fileName, nil)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println(file)
+	slog.Info("Retrieved file", "name", file.Name, "mime_type", file.MIMEType, "state", file.State)
 	// [END files_get]
 	return file, err
 }
@@ -328,8 +328,9 @@ func FilesDelete() error {
 	// [START files_delete]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
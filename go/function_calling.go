@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 
 	"google.golang.org/genai"
@@ -62,8 +63,9 @@ func FunctionCalling() error {
 	// [START function_calling]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -113,11 +115,11 @@ func FunctionCalling() error {
 
 	// Assume the response includes a list of function calls.
 	if len(genContentResp.FunctionCalls()) == 0 {
-		log.Println("No function call returned from the AI.")
+		slog.Warn("No function call returned from the AI.")
 		return nil
 	}
 	functionCall := genContentResp.FunctionCalls()[0]
-	log.Printf("Function call: %+v\n", functionCall)
+	slog.Info("Function call", "name", functionCall.Name, "args", functionCall.Args)
 
 	// Marshal the Args map into JSON bytes.
 	argsMap, err := json.Marshal(functionCall.Args)
@@ -145,7 +147,7 @@ func FunctionCalling() error {
 		default:
 			return fmt.Errorf("unimplemented function: %s", functionCall.Name)
 	}
-	log.Printf("Function result: %v\n", result)
+	slog.Info("Function result", "name", functionCall.Name, "result", result)
 
 	// Prepare the final result message as content.
 	resultContents := []*genai.Content{
@@ -0,0 +1,177 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// Hedger sends a second, identical request when the first one is slower
+// than a chosen percentile of recent latencies, returns whichever answer
+// arrives first and cancels the other. It is safe for concurrent use.
+type Hedger struct {
+	// Percentile of observed latency after which the hedge fires, e.g. 0.95.
+	Percentile float64
+	// InitialDelay is used until MinSamples latencies have been observed.
+	InitialDelay time.Duration
+	MinSamples   int
+	// Breaker, if set, guards every attempt.
+	Breaker *CircuitBreaker
+
+	mu        sync.Mutex
+	latencies []time.Duration
+	next      int
+}
+
+// hedgeWindow is the number of recent latencies kept for the percentile.
+const hedgeWindow = 100
+
+// NewHedger returns a Hedger that fires at the given latency percentile.
+func NewHedger(percentile float64, initialDelay time.Duration) *Hedger {
+	return &Hedger{
+		Percentile:   percentile,
+		InitialDelay: initialDelay,
+		MinSamples:   20,
+	}
+}
+
+// Delay returns how long the first attempt may run before the hedge fires.
+func (h *Hedger) Delay() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.latencies) < max(h.MinSamples, 1) {
+		return h.InitialDelay
+	}
+	sorted := slices.Clone(h.latencies)
+	slices.Sort(sorted)
+	i := int(math.Ceil(h.Percentile*float64(len(sorted)))) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
+
+func (h *Hedger) observe(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.latencies) < hedgeWindow {
+		h.latencies = append(h.latencies, d)
+		return
+	}
+	h.latencies[h.next] = d
+	h.next = (h.next + 1) % hedgeWindow
+}
+
+// GenerateContent runs GenerateContent for model with hedging.
+func (h *Hedger) GenerateContent(
+	ctx context.Context,
+	client *genai.Client,
+	model string,
+	contents []*genai.Content,
+	config *genai.GenerateContentConfig,
+) (*genai.GenerateContentResponse, error) {
+	return h.do(ctx, model, func(ctx context.Context) (*genai.GenerateContentResponse, error) {
+		return client.Models.GenerateContent(ctx, model, contents, config)
+	})
+}
+
+type hedgeResult struct {
+	resp *genai.GenerateContentResponse
+	err  error
+}
+
+func (h *Hedger) do(
+	ctx context.Context,
+	key string,
+	call func(context.Context) (*genai.GenerateContentResponse, error),
+) (*genai.GenerateContentResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	// Cancelling on return stops whichever attempt lost.
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	start := time.Now()
+	launch := func() error {
+		if h.Breaker != nil {
+			if err := h.Breaker.Allow(key); err != nil {
+				return err
+			}
+		}
+		go func() {
+			resp, err := call(ctx)
+			if h.Breaker != nil {
+				h.Breaker.Record(key, err)
+			}
+			results <- hedgeResult{resp, err}
+		}()
+		return nil
+	}
+
+	if err := launch(); err != nil {
+		return nil, err
+	}
+	inFlight := 1
+	timer := time.NewTimer(h.Delay())
+	defer timer.Stop()
+
+	var firstErr error
+	for {
+		select {
+		case <-timer.C:
+			// The breaker may refuse the hedge; the first attempt keeps running.
+			if launch() == nil {
+				inFlight++
+			}
+		case r := <-results:
+			inFlight--
+			if r.err == nil {
+				h.observe(time.Since(start))
+				return r.resp, nil
+			}
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			if inFlight == 0 {
+				return nil, fmt.Errorf("hedge: %w", firstErr)
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func HedgedGenerateContent() (*genai.GenerateContentResponse, error) {
+	// [START hedged_generate_content]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Fire a second request when the first is slower than the p95 latency,
+	// and stop calling a model after five consecutive failures.
+	hedger := NewHedger(0.95, 2*time.Second)
+	hedger.Breaker = NewCircuitBreaker(5, 30*time.Second)
+
+	response, err := hedger.GenerateContent(
+		ctx,
+		client,
+		"gemini-2.0-flash",
+		genai.Text("Write a story about a magic backpack."),
+		nil,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printResponse(response)
+	// [END hedged_generate_content]
+	return response, err
+}
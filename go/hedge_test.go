@@ -0,0 +1,99 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestHedgerFiresSecondRequest(t *testing.T) {
+	var calls, cancelled atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices a client disconnect once the body is read.
+		io.Copy(io.Discard, r.Body)
+		if calls.Add(1) == 1 {
+			// The first request stalls until the hedge wins and cancels it.
+			select {
+			case <-r.Context().Done():
+				cancelled.Add(1)
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, textResponse("fast"))
+	}))
+	h := NewHedger(0.95, 20*time.Millisecond)
+
+	start := time.Now()
+	resp, err := h.GenerateContent(context.Background(), client, "gemini-2.0-flash", genai.Text("hi"), nil)
+	if err != nil {
+		t.Fatalf("GenerateContent returned an error: %v", err)
+	}
+	if resp.Text() != "fast" {
+		t.Errorf("Text() = %q, want %q", resp.Text(), "fast")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("took %v, the hedge should have answered quickly", elapsed)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for cancelled.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if cancelled.Load() != 1 {
+		t.Error("losing request was not cancelled")
+	}
+}
+
+func TestHedgerNoHedgeWhenFast(t *testing.T) {
+	var calls atomic.Int32
+	h := NewHedger(0.95, time.Second)
+	resp, err := h.do(context.Background(), "m", func(context.Context) (*genai.GenerateContentResponse, error) {
+		calls.Add(1)
+		return &genai.GenerateContentResponse{}, nil
+	})
+	if err != nil || resp == nil {
+		t.Fatalf("do = %v, %v", resp, err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("made %d calls, want 1", got)
+	}
+}
+
+func TestHedgerBothFail(t *testing.T) {
+	h := NewHedger(0.95, time.Millisecond)
+	boom := errors.New("boom")
+	_, err := h.do(context.Background(), "m", func(context.Context) (*genai.GenerateContentResponse, error) {
+		time.Sleep(10 * time.Millisecond)
+		return nil, boom
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("err = %v, want %v", err, boom)
+	}
+}
+
+func TestHedgerDelayPercentile(t *testing.T) {
+	h := NewHedger(0.9, time.Second)
+	h.MinSamples = 10
+	if got := h.Delay(); got != time.Second {
+		t.Errorf("Delay() before samples = %v, want %v", got, time.Second)
+	}
+	for i := 1; i <= 10; i++ {
+		h.observe(time.Duration(i) * time.Millisecond)
+	}
+	if got := h.Delay(); got != 9*time.Millisecond {
+		t.Errorf("Delay() = %v, want 9ms", got)
+	}
+}
+
+func TestHedgedGenerateContent(t *testing.T) {
+	_, err := HedgedGenerateContent()
+	if err != nil {
+		t.Errorf("HedgedGenerateContent returned an error: %v", err)
+	}
+}
@@ -0,0 +1,319 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/genai"
+)
+
+// ImageOptions configures PreprocessImage.
+type ImageOptions struct {
+	// MaxDimension is the longest side of the output in pixels. Zero means
+	// 768, which fits every image in a single tile of the default
+	// heuristics.
+	MaxDimension int
+	// Format is "jpeg" or "png". If empty, PNG input stays PNG and anything
+	// else becomes JPEG.
+	Format string
+	// Quality is the JPEG quality, 1-100. Zero means 85.
+	Quality int
+	// MaxBytes, if set, lowers the JPEG quality in steps until the output fits.
+	MaxBytes int
+	// Heuristics estimates the image tokens before and after. Zero fields
+	// take their value from DefaultTokenHeuristics.
+	Heuristics TokenHeuristics
+}
+
+// PreprocessedImage is an image ready to be sent to the model.
+type PreprocessedImage struct {
+	Data           []byte
+	MIMEType       string
+	Width, Height  int
+	OriginalWidth  int
+	OriginalHeight int
+	OriginalBytes  int
+	Quality        int
+	// HadEXIF and HadGPS report metadata that was present in the input.
+	// Re-encoding never writes metadata, so both are always stripped.
+	HadEXIF, HadGPS bool
+	// OriginalTokens and Tokens are estimated with ImageOptions.Heuristics.
+	OriginalTokens, Tokens int
+}
+
+// TokensSaved is the estimated reduction in prompt tokens.
+func (p *PreprocessedImage) TokensSaved() int {
+	return p.OriginalTokens - p.Tokens
+}
+
+// Part returns the image as inline data.
+func (p *PreprocessedImage) Part() *genai.Part {
+	return genai.NewPartFromBytes(p.Data, p.MIMEType)
+}
+
+// PreprocessImageFile reads and preprocesses the image at path.
+func PreprocessImageFile(path string, opts *ImageOptions) (*PreprocessedImage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("preprocess image: %w", err)
+	}
+	return PreprocessImage(data, opts)
+}
+
+// PreprocessImage downsizes a JPEG, PNG or GIF image, applies its EXIF
+// orientation and re-encodes it without metadata.
+func PreprocessImage(data []byte, opts *ImageOptions) (*PreprocessedImage, error) {
+	var o ImageOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.MaxDimension == 0 {
+		o.MaxDimension = 768
+	}
+	if o.Quality == 0 {
+		o.Quality = 85
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("preprocess image: %w", err)
+	}
+	if o.Format == "" {
+		o.Format = "jpeg"
+		if format == "png" {
+			o.Format = "png"
+		}
+	}
+	h := o.Heuristics
+	out := &PreprocessedImage{
+		OriginalWidth:  src.Bounds().Dx(),
+		OriginalHeight: src.Bounds().Dy(),
+		OriginalBytes:  len(data),
+		OriginalTokens: h.Image(src.Bounds().Dx(), src.Bounds().Dy()),
+	}
+	if format == "jpeg" {
+		orientation, hadEXIF, hadGPS := readEXIF(data)
+		out.HadEXIF, out.HadGPS = hadEXIF, hadGPS
+		src = orient(src, orientation)
+	}
+
+	img := downscale(src, o.MaxDimension)
+	out.Width, out.Height = img.Bounds().Dx(), img.Bounds().Dy()
+	out.Tokens = h.Image(out.Width, out.Height)
+
+	var buf bytes.Buffer
+	switch o.Format {
+	case "png":
+		out.MIMEType = "image/png"
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("preprocess image: %w", err)
+		}
+	case "jpeg":
+		out.MIMEType = "image/jpeg"
+		// JPEG has no alpha channel, so flatten onto white.
+		flat := image.NewRGBA(img.Bounds())
+		draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+		for q := o.Quality; ; q = max(q-10, 10) {
+			buf.Reset()
+			if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: q}); err != nil {
+				return nil, fmt.Errorf("preprocess image: %w", err)
+			}
+			out.Quality = q
+			if o.MaxBytes == 0 || buf.Len() <= o.MaxBytes || q == 10 {
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("preprocess image: unknown format %q", o.Format)
+	}
+	out.Data = buf.Bytes()
+	return out, nil
+}
+
+// downscale shrinks img so that neither side exceeds maxDim, averaging the
+// source pixels that fall in each output pixel.
+func downscale(img image.Image, maxDim int) image.Image {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if sw <= maxDim && sh <= maxDim {
+		return img
+	}
+	dw, dh := maxDim, max(sh*maxDim/sw, 1)
+	if sh > sw {
+		dw, dh = max(sw*maxDim/sh, 1), maxDim
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, sw, sh))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := range dh {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := range dw {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+			var r, g, bl, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r, g, bl, a = r+int(p[0]), g+int(p[1]), bl+int(p[2]), a+int(p[3])
+					n++
+				}
+			}
+			i := y*dst.Stride + x*4
+			dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2], dst.Pix[i+3] = uint8(r/n), uint8(g/n), uint8(bl/n), uint8(a/n)
+		}
+	}
+	return dst
+}
+
+// orient applies an EXIF orientation (1-8) so the pixels display upright
+// once the metadata is gone.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := range h {
+		for x := range w {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// readEXIF finds the APP1 EXIF segment of a JPEG and returns the orientation
+// tag and whether EXIF and GPS data are present.
+func readEXIF(data []byte) (orientation int, hadEXIF, hadGPS bool) {
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		if marker == 0xDA { // start of scan: no more metadata
+			break
+		}
+		n := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := min(i+2+n, len(data))
+		seg := data[i+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			hadEXIF = true
+			orientation, hadGPS, _ = parseIFD0(seg[6:])
+			return orientation, hadEXIF, hadGPS
+		}
+		i = end
+	}
+	return 0, false, false
+}
+
+// parseIFD0 reads the orientation (0x0112) and GPS pointer (0x8825) tags
+// from the first IFD of a TIFF structure.
+func parseIFD0(tiff []byte) (orientation int, hasGPS bool, err error) {
+	if len(tiff) < 8 {
+		return 0, false, errors.New("exif: short header")
+	}
+	var bo binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		bo = binary.LittleEndian
+	case "MM":
+		bo = binary.BigEndian
+	default:
+		return 0, false, errors.New("exif: bad byte order")
+	}
+	off := int(bo.Uint32(tiff[4:]))
+	if off+2 > len(tiff) {
+		return 0, false, errors.New("exif: bad IFD offset")
+	}
+	count := int(bo.Uint16(tiff[off:]))
+	for i := range count {
+		e := off + 2 + i*12
+		if e+12 > len(tiff) {
+			break
+		}
+		switch bo.Uint16(tiff[e:]) {
+		case 0x0112:
+			orientation = int(bo.Uint16(tiff[e+8:]))
+		case 0x8825:
+			hasGPS = true
+		}
+	}
+	return orientation, hasGPS, nil
+}
+
+func TextGenMultimodalPreprocessedImages() (*genai.GenerateContentResponse, error) {
+	// [START text_gen_multimodal_preprocessed_images]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	parts := []*genai.Part{
+		genai.NewPartFromText("What is the difference between both of these instruments?"),
+	}
+	for _, name := range []string{"organ.jpg", "Cajun_instruments.jpg"} {
+		// Shrink each photo and drop its metadata before sending it inline.
+		img, err := PreprocessImageFile(filepath.Join(getMedia(), name), &ImageOptions{MaxDimension: 768, Quality: 80})
+		if err != nil {
+			log.Fatal(err)
+		}
+		slog.Info("Preprocessed image",
+			"name", name,
+			"original_size", fmt.Sprintf("%dx%d", img.OriginalWidth, img.OriginalHeight),
+			"size", fmt.Sprintf("%dx%d", img.Width, img.Height),
+			"original_bytes", img.OriginalBytes,
+			"bytes", len(img.Data),
+			"tokens_saved", img.TokensSaved(),
+			"gps_stripped", img.HadGPS,
+		)
+		parts = append(parts, img.Part())
+	}
+	contents := []*genai.Content{
+		genai.NewContentFromParts(parts, "user"),
+	}
+
+	response, err := client.Models.GenerateContent(ctx, "gemini-2.0-flash", contents, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printResponse(response)
+	// [END text_gen_multimodal_preprocessed_images]
+	return response, err
+}
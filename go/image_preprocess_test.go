@@ -0,0 +1,136 @@
+package examples
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"path/filepath"
+	"testing"
+)
+
+// jpegWithEXIF encodes img and inserts an EXIF segment carrying the given
+// orientation and a GPS pointer.
+func jpegWithEXIF(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+	var enc bytes.Buffer
+	if err := jpeg.Encode(&enc, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	for _, e := range [][3]uint32{{0x0112, 3, uint32(orientation)}, {0x8825, 4, 0}} {
+		tiff = binary.LittleEndian.AppendUint16(tiff, uint16(e[0]))
+		tiff = binary.LittleEndian.AppendUint16(tiff, uint16(e[1]))
+		tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+		tiff = binary.LittleEndian.AppendUint32(tiff, e[2])
+	}
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+	seg := append([]byte("Exif\x00\x00"), tiff...)
+
+	out := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	out = binary.BigEndian.AppendUint16(out, uint16(len(seg)+2))
+	out = append(out, seg...)
+	return append(out, enc.Bytes()[2:]...)
+}
+
+func TestPreprocessImageFile(t *testing.T) {
+	img, err := PreprocessImageFile(filepath.Join(getMedia(), "organ.jpg"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 768 || img.Height != 510 || img.MIMEType != "image/jpeg" {
+		t.Errorf("got %dx%d %s, want 768x510 image/jpeg", img.Width, img.Height, img.MIMEType)
+	}
+	if !img.HadEXIF || bytes.Contains(img.Data, []byte("Exif")) {
+		t.Errorf("HadEXIF = %v; output still has EXIF: %v", img.HadEXIF, bytes.Contains(img.Data, []byte("Exif")))
+	}
+	// The original is tiled into six 768px squares; the output needs one.
+	if img.OriginalTokens != 6*258 || img.TokensSaved() != 5*258 {
+		t.Errorf("tokens %d -> %d", img.OriginalTokens, img.Tokens)
+	}
+	if len(img.Data) >= img.OriginalBytes {
+		t.Errorf("output is %d bytes, original %d", len(img.Data), img.OriginalBytes)
+	}
+}
+
+func TestPreprocessImageHeuristics(t *testing.T) {
+	path := filepath.Join(getMedia(), "organ.jpg")
+	img, err := PreprocessImageFile(path, &ImageOptions{Heuristics: TokenHeuristics{TokensPerImageTile: 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.OriginalTokens != 600 || img.Tokens != 100 {
+		t.Errorf("tokens %d -> %d, want 600 -> 100", img.OriginalTokens, img.Tokens)
+	}
+	// A model that charges one tile for any image saves nothing.
+	img, err = PreprocessImageFile(path, &ImageOptions{Heuristics: TokenHeuristics{MaxImageTiles: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.TokensSaved() != 0 {
+		t.Errorf("TokensSaved = %d with a one-tile cap, want 0", img.TokensSaved())
+	}
+}
+
+func TestPreprocessImageOrientationAndGPS(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for x := range 20 {
+		for y := range 20 {
+			src.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	// Orientation 6: the stored image must be rotated 90° clockwise.
+	img, err := PreprocessImage(jpegWithEXIF(t, src, 6), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !img.HadGPS || bytes.Contains(img.Data, []byte("Exif")) {
+		t.Errorf("HadGPS = %v, want GPS detected and stripped", img.HadGPS)
+	}
+	out, err := jpeg.Decode(bytes.NewReader(img.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := out.Bounds(); b.Dx() != 20 || b.Dy() != 40 {
+		t.Fatalf("rotated size = %v, want 20x40", b)
+	}
+	// The red left half of the source ends up on top.
+	if r, _, _, _ := out.At(10, 5).RGBA(); r < 0xC000 {
+		t.Errorf("top of rotated image is not red")
+	}
+}
+
+func TestPreprocessImagePNGAndMaxBytes(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 1000, 500))
+	for i := range src.Pix {
+		src.Pix[i] = uint8(i * 7)
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, src)
+
+	img, err := PreprocessImage(buf.Bytes(), &ImageOptions{MaxDimension: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.MIMEType != "image/png" || img.Width != 100 || img.Height != 50 {
+		t.Errorf("got %dx%d %s", img.Width, img.Height, img.MIMEType)
+	}
+
+	img, err = PreprocessImage(buf.Bytes(), &ImageOptions{Format: "jpeg", Quality: 95, MaxBytes: 6000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Quality >= 95 || (len(img.Data) > 6000 && img.Quality != 10) {
+		t.Errorf("quality %d produced %d bytes", img.Quality, len(img.Data))
+	}
+}
+
+func TestTextGenMultimodalPreprocessedImages(t *testing.T) {
+	_, err := TextGenMultimodalPreprocessedImages()
+	if err != nil {
+		t.Errorf("TextGenMultimodalPreprocessedImages returned an error: %v", err)
+	}
+}
@@ -0,0 +1,295 @@
+package examples
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// ModelRate is the price of a model in USD per million tokens. Prompts
+// longer than LongContextThreshold tokens are billed at the Long* prices.
+type ModelRate struct {
+	InputPerMillion       float64
+	OutputPerMillion      float64
+	CachedInputPerMillion float64
+
+	LongContextThreshold      int
+	LongInputPerMillion       float64
+	LongOutputPerMillion      float64
+	LongCachedInputPerMillion float64
+
+	// CacheStoragePerMillionHour is the price of keeping a million tokens in
+	// a context cache for an hour.
+	CacheStoragePerMillionHour float64
+	// MinCacheTokens is the smallest context the model will cache.
+	MinCacheTokens int
+}
+
+// RateTable maps model IDs to their prices. A stable or experimental version
+// of a listed model, such as gemini-2.0-flash-001, gemini-1.5-flash-latest or
+// gemini-2.0-flash-exp-0205, is priced as that model. Other variants, such as
+// gemini-2.0-flash-lite, must be listed themselves.
+type RateTable map[string]ModelRate
+
+// ExampleRates holds illustrative prices for the models used in the examples.
+// Check the Gemini API pricing page before relying on them.
+var ExampleRates = RateTable{
+	"gemini-2.0-flash": {
+		InputPerMillion:       0.10,
+		OutputPerMillion:      0.40,
+		CachedInputPerMillion: 0.025,
+
+		CacheStoragePerMillionHour: 1.00,
+		MinCacheTokens:             4096,
+	},
+	"gemini-1.5-flash": {
+		InputPerMillion:           0.075,
+		OutputPerMillion:          0.30,
+		CachedInputPerMillion:     0.01875,
+		LongContextThreshold:      128000,
+		LongInputPerMillion:       0.15,
+		LongOutputPerMillion:      0.60,
+		LongCachedInputPerMillion: 0.0375,
+
+		CacheStoragePerMillionHour: 1.00,
+		MinCacheTokens:             32768,
+	},
+	"gemini-2.5-pro": {
+		InputPerMillion:           1.25,
+		OutputPerMillion:          10.00,
+		CachedInputPerMillion:     0.31,
+		LongContextThreshold:      200000,
+		LongInputPerMillion:       2.50,
+		LongOutputPerMillion:      15.00,
+		LongCachedInputPerMillion: 0.625,
+
+		CacheStoragePerMillionHour: 4.50,
+		MinCacheTokens:             4096,
+	},
+}
+
+// Lookup returns the rate for model.
+func (rt RateTable) Lookup(model string) (ModelRate, bool) {
+	model = strings.TrimPrefix(model, "models/")
+	if r, ok := rt[model]; ok {
+		return r, true
+	}
+	if m := modelVersionRE.FindStringSubmatch(model); m != nil {
+		r, ok := rt[m[1]]
+		return r, ok
+	}
+	return ModelRate{}, false
+}
+
+// modelVersionRE splits a model ID into its base ID and a version suffix.
+var modelVersionRE = regexp.MustCompile(`^(.+?)-(?:\d{3}|latest|exp(?:-.+)?)$`)
+
+// Cost prices a request. Cached tokens are part of the prompt count and are
+// billed at the cached rate; thoughts are billed as output.
+func (r ModelRate) Cost(promptTokens, cachedTokens, outputTokens int) float64 {
+	in, out, cached := r.InputPerMillion, r.OutputPerMillion, r.CachedInputPerMillion
+	if r.LongContextThreshold > 0 && promptTokens > r.LongContextThreshold {
+		in, out, cached = r.LongInputPerMillion, r.LongOutputPerMillion, r.LongCachedInputPerMillion
+	}
+	uncached := max(promptTokens-cachedTokens, 0)
+	return (float64(uncached)*in + float64(cachedTokens)*cached + float64(outputTokens)*out) / 1e6
+}
+
+// UsageTags attribute a request to whoever should pay for it.
+type UsageTags struct {
+	User    string `json:"user,omitempty"`
+	Feature string `json:"feature,omitempty"`
+	Model   string `json:"model"`
+}
+
+// UsageEntry is one request recorded in a Ledger.
+type UsageEntry struct {
+	Time time.Time `json:"time"`
+	UsageTags
+	PromptTokens     int     `json:"promptTokens"`
+	CandidatesTokens int     `json:"candidatesTokens"`
+	ThoughtsTokens   int     `json:"thoughtsTokens"`
+	CachedTokens     int     `json:"cachedTokens"`
+	Cost             float64 `json:"cost"`
+	// Priced is false when the model is missing from the rate table.
+	Priced bool `json:"priced"`
+}
+
+// Ledger accumulates token usage and cost. It is safe for concurrent use.
+type Ledger struct {
+	Rates RateTable
+
+	mu      sync.Mutex
+	entries []UsageEntry
+	now     func() time.Time
+}
+
+// NewLedger returns an empty ledger that prices requests with rates.
+func NewLedger(rates RateTable) *Ledger {
+	return &Ledger{Rates: rates}
+}
+
+func (l *Ledger) clock() time.Time {
+	if l.now != nil {
+		return l.now()
+	}
+	return time.Now()
+}
+
+// Record adds the usage of one response to the ledger and returns the entry.
+func (l *Ledger) Record(tags UsageTags, u *genai.GenerateContentResponseUsageMetadata) UsageEntry {
+	e := UsageEntry{Time: l.clock().UTC(), UsageTags: tags}
+	if u != nil {
+		e.PromptTokens = int(u.PromptTokenCount)
+		e.CandidatesTokens = int(u.CandidatesTokenCount)
+		e.ThoughtsTokens = int(u.ThoughtsTokenCount)
+		e.CachedTokens = int(u.CachedContentTokenCount)
+	}
+	if rate, ok := l.Rates.Lookup(tags.Model); ok {
+		e.Cost = rate.Cost(e.PromptTokens, e.CachedTokens, e.CandidatesTokens+e.ThoughtsTokens)
+		e.Priced = true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, e)
+	return e
+}
+
+// Entries returns a copy of everything recorded so far.
+func (l *Ledger) Entries() []UsageEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.entries)
+}
+
+// DailyRollup sums the entries of one UTC day for one set of tags.
+type DailyRollup struct {
+	Day string `json:"day"`
+	UsageTags
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"promptTokens"`
+	CandidatesTokens int     `json:"candidatesTokens"`
+	ThoughtsTokens   int     `json:"thoughtsTokens"`
+	CachedTokens     int     `json:"cachedTokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// DailyRollups groups the entries by day, user, feature and model.
+func (l *Ledger) DailyRollups() []DailyRollup {
+	type key struct {
+		day string
+		UsageTags
+	}
+	byKey := make(map[key]*DailyRollup)
+	for _, e := range l.Entries() {
+		k := key{e.Time.Format(time.DateOnly), e.UsageTags}
+		r, ok := byKey[k]
+		if !ok {
+			r = &DailyRollup{Day: k.day, UsageTags: e.UsageTags}
+			byKey[k] = r
+		}
+		r.Requests++
+		r.PromptTokens += e.PromptTokens
+		r.CandidatesTokens += e.CandidatesTokens
+		r.ThoughtsTokens += e.ThoughtsTokens
+		r.CachedTokens += e.CachedTokens
+		r.Cost += e.Cost
+	}
+
+	rollups := make([]DailyRollup, 0, len(byKey))
+	for _, r := range byKey {
+		rollups = append(rollups, *r)
+	}
+	slices.SortFunc(rollups, func(a, b DailyRollup) int {
+		return cmp.Or(
+			cmp.Compare(a.Day, b.Day),
+			cmp.Compare(a.User, b.User),
+			cmp.Compare(a.Feature, b.Feature),
+			cmp.Compare(a.Model, b.Model),
+		)
+	})
+	return rollups
+}
+
+// WriteDailyCSV writes the daily rollups as CSV with a header row.
+func (l *Ledger) WriteDailyCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"day", "user", "feature", "model", "requests",
+		"prompt_tokens", "candidates_tokens", "thoughts_tokens", "cached_tokens", "cost_usd",
+	})
+	for _, r := range l.DailyRollups() {
+		cw.Write([]string{
+			r.Day, r.User, r.Feature, r.Model,
+			strconv.Itoa(r.Requests),
+			strconv.Itoa(r.PromptTokens),
+			strconv.Itoa(r.CandidatesTokens),
+			strconv.Itoa(r.ThoughtsTokens),
+			strconv.Itoa(r.CachedTokens),
+			strconv.FormatFloat(r.Cost, 'f', 6, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteDailyJSON writes the daily rollups as a JSON array.
+func (l *Ledger) WriteDailyJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(l.DailyRollups()); err != nil {
+		return fmt.Errorf("ledger: %w", err)
+	}
+	return nil
+}
+
+func TokensUsageLedger() error {
+	// [START tokens_usage_ledger]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ledger := NewLedger(ExampleRates)
+	prompts := []string{
+		"The quick brown fox jumps over the lazy dog.",
+		"In one sentence, explain how a computer works to a young child.",
+	}
+	for _, prompt := range prompts {
+		response, err := client.Models.GenerateContent(ctx, "gemini-2.0-flash", genai.Text(prompt), nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		entry := ledger.Record(UsageTags{
+			User:    "example-user",
+			Feature: "tokens",
+			Model:   "gemini-2.0-flash",
+		}, response.UsageMetadata)
+		slog.Info("Usage", "prompt_tokens", entry.PromptTokens, "output_tokens", entry.CandidatesTokens, "cost_usd", entry.Cost)
+	}
+
+	if err := ledger.WriteDailyCSV(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+	// [END tokens_usage_ledger]
+	return err
+}
@@ -0,0 +1,143 @@
+package examples
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-12
+}
+
+func TestModelRateCost(t *testing.T) {
+	rate := ExampleRates["gemini-1.5-flash"]
+	// 1000 prompt tokens of which 400 cached, 200 output.
+	want := (600*0.075 + 400*0.01875 + 200*0.30) / 1e6
+	if got := rate.Cost(1000, 400, 200); !almostEqual(got, want) {
+		t.Errorf("Cost = %v, want %v", got, want)
+	}
+	// Above the threshold the long-context tier applies.
+	want = (200000*0.15 + 100*0.60) / 1e6
+	if got := rate.Cost(200000, 0, 100); !almostEqual(got, want) {
+		t.Errorf("long context Cost = %v, want %v", got, want)
+	}
+}
+
+func TestRateTableLookup(t *testing.T) {
+	for _, model := range []string{
+		"gemini-2.0-flash",
+		"models/gemini-2.0-flash-001",
+		"gemini-1.5-flash-002",
+		"gemini-1.5-flash-latest",
+		"gemini-2.0-flash-exp",
+		"gemini-2.5-pro-exp-03-25",
+	} {
+		if _, ok := ExampleRates.Lookup(model); !ok {
+			t.Errorf("Lookup(%q) found no rate", model)
+		}
+	}
+	for _, model := range []string{
+		"text-embedding-004",
+		"gemini-2.0-flash-lite",
+		"gemini-1.5-flash-8b",
+		"gemini-1.5-flash-8b-001",
+		"gemini-2.0-flash-thinking-exp",
+	} {
+		if _, ok := ExampleRates.Lookup(model); ok {
+			t.Errorf("Lookup(%q) priced an unlisted model", model)
+		}
+	}
+}
+
+func newTestLedger() (*Ledger, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2025, 4, 1, 23, 0, 0, 0, time.UTC)}
+	l := NewLedger(ExampleRates)
+	l.now = clock.Now
+	return l, clock
+}
+
+func TestLedgerDailyRollups(t *testing.T) {
+	l, clock := newTestLedger()
+	usage := &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:     100,
+		CandidatesTokenCount: 10,
+		ThoughtsTokenCount:   5,
+	}
+	tags := UsageTags{User: "ada", Feature: "chat", Model: "gemini-2.0-flash"}
+	l.Record(tags, usage)
+	l.Record(tags, usage)
+	l.Record(UsageTags{User: "bob", Feature: "chat", Model: "unknown-model"}, usage)
+	clock.Advance(2 * time.Hour)
+	l.Record(tags, usage)
+
+	rollups := l.DailyRollups()
+	if len(rollups) != 3 {
+		t.Fatalf("got %d rollups, want 3: %+v", len(rollups), rollups)
+	}
+	first := rollups[0]
+	if first.Day != "2025-04-01" || first.User != "ada" || first.Requests != 2 || first.PromptTokens != 200 {
+		t.Errorf("first rollup = %+v", first)
+	}
+	if want := 2 * (100*0.10 + 15*0.40) / 1e6; !almostEqual(first.Cost, want) {
+		t.Errorf("first rollup cost = %v, want %v", first.Cost, want)
+	}
+	if rollups[1].User != "bob" || rollups[1].Cost != 0 {
+		t.Errorf("unpriced rollup = %+v", rollups[1])
+	}
+	if rollups[2].Day != "2025-04-02" {
+		t.Errorf("last rollup day = %s", rollups[2].Day)
+	}
+}
+
+func TestLedgerZeroValue(t *testing.T) {
+	l := &Ledger{Rates: ExampleRates}
+	e := l.Record(UsageTags{Model: "gemini-2.0-flash"}, &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10})
+	if e.Time.IsZero() || !e.Priced || len(l.DailyRollups()) != 1 {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
+func TestLedgerExport(t *testing.T) {
+	l, _ := newTestLedger()
+	l.Record(UsageTags{User: "ada", Model: "gemini-2.0-flash"}, &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:        1000,
+		CachedContentTokenCount: 1000,
+	})
+
+	var csvOut bytes.Buffer
+	if err := l.WriteDailyCSV(&csvOut); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "day,user,feature,model") {
+		t.Fatalf("unexpected CSV:\n%s", csvOut.String())
+	}
+	if want := "2025-04-01,ada,,gemini-2.0-flash,1,1000,0,0,1000,0.000025"; lines[1] != want {
+		t.Errorf("CSV row = %q, want %q", lines[1], want)
+	}
+
+	var jsonOut bytes.Buffer
+	if err := l.WriteDailyJSON(&jsonOut); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []DailyRollup
+	if err := json.Unmarshal(jsonOut.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || decoded[0].CachedTokens != 1000 || decoded[0].User != "ada" {
+		t.Errorf("decoded JSON = %+v", decoded)
+	}
+}
+
+func TestTokensUsageLedger(t *testing.T) {
+	err := TokensUsageLedger()
+	if err != nil {
+		t.Errorf("TokensUsageLedger returned an error: %v", err)
+	}
+}
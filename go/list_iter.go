@@ -0,0 +1,58 @@
+package examples
+
+import (
+	"iter"
+	"slices"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// Filter yields the items of seq, such as client.Files.All(ctx), that pass
+// every filter. Errors are passed through. Breaking out of the loop stops
+// seq, so no further pages are fetched.
+func Filter[T any](seq iter.Seq2[*T, error], filters ...func(*T) bool) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		for item, err := range seq {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if passes(item, filters) && !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+func passes[T any](item *T, filters []func(*T) bool) bool {
+	for _, keep := range filters {
+		if !keep(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// ModelSupports keeps models that support action, such as "generateContent"
+// or "embedContent".
+func ModelSupports(action string) func(*genai.Model) bool {
+	return func(m *genai.Model) bool {
+		return slices.Contains(m.SupportedActions, action)
+	}
+}
+
+// FileInState keeps files in state.
+func FileInState(state genai.FileState) func(*genai.File) bool {
+	return func(f *genai.File) bool {
+		return f.State == state
+	}
+}
+
+// CacheForModel keeps caches of model, with or without the "models/" prefix.
+func CacheForModel(model string) func(*genai.CachedContent) bool {
+	model = strings.TrimPrefix(model, "models/")
+	return func(c *genai.CachedContent) bool {
+		return strings.TrimPrefix(c.Model, "models/") == model
+	}
+}
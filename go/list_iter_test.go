@@ -0,0 +1,117 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestFilterFiles(t *testing.T) {
+	files := newFakeFiles()
+	for i := range 5 {
+		state := genai.FileStateActive
+		if i%2 == 1 {
+			state = genai.FileStateProcessing
+		}
+		files.add(&genai.File{Name: fmt.Sprintf("files/f%d", i), State: state})
+	}
+	var lists atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1beta/files" {
+			lists.Add(1)
+		}
+		files.ServeHTTP(w, r)
+	}))
+	ctx := context.Background()
+
+	var names []string
+	for f, err := range Filter(client.Files.All(ctx)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, f.Name)
+	}
+	if len(names) != 5 || lists.Load() != 3 {
+		t.Errorf("got %v in %d pages, want 5 files in 3", names, lists.Load())
+	}
+
+	active := 0
+	for _, err := range Filter(client.Files.All(ctx), FileInState(genai.FileStateActive)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		active++
+	}
+	if active != 3 {
+		t.Errorf("got %d active files, want 3", active)
+	}
+
+	// Stopping after the first page's items must not fetch the rest.
+	lists.Store(0)
+	for f := range Filter(client.Files.All(ctx)) {
+		if f.Name == "files/f1" {
+			break
+		}
+	}
+	if lists.Load() != 1 {
+		t.Errorf("fetched %d pages after an early break, want 1", lists.Load())
+	}
+}
+
+func TestFilterCaches(t *testing.T) {
+	caches := newFakeCaches()
+	client := newTestClient(t, caches)
+	ctx := context.Background()
+	for _, model := range []string{"gemini-1.5-flash-001", "gemini-2.0-flash", "gemini-1.5-flash-001"} {
+		if _, err := client.Caches.Create(ctx, model, &genai.CreateCachedContentConfig{Contents: genai.Text("x")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n := 0
+	for c, err := range Filter(client.Caches.All(ctx), CacheForModel("models/gemini-1.5-flash-001")) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Model != "models/gemini-1.5-flash-001" {
+			t.Errorf("filter let through %s", c.Model)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Errorf("got %d caches, want 2", n)
+	}
+}
+
+func TestFilterModelsError(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("pageToken"))
+		if page == 1 {
+			writeAPIError(w, http.StatusInternalServerError, "INTERNAL", "boom")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"models": []any{
+				map[string]any{"name": "models/gemini-2.0-flash", "supportedGenerationMethods": []string{"generateContent"}},
+				map[string]any{"name": "models/text-embedding-004", "supportedGenerationMethods": []string{"embedContent"}},
+			},
+			"nextPageToken": "1",
+		})
+	}))
+	var names []string
+	var gotErr error
+	for m, err := range Filter(client.Models.All(context.Background()), ModelSupports("embedContent")) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		names = append(names, m.Name)
+	}
+	if len(names) != 1 || names[0] != "models/text-embedding-004" || gotErr == nil {
+		t.Errorf("got %v, err %v; want the embedding model then an error", names, gotErr)
+	}
+}
@@ -0,0 +1,269 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/genai"
+)
+
+// LogConfig controls the structured records the examples emit.
+type LogConfig struct {
+	// Logger receives the records. If nil, slog.Default() is used.
+	Logger *slog.Logger
+	// RedactPrompts replaces prompt text with its length.
+	RedactPrompts bool
+	// RedactResponses replaces response text with its length.
+	RedactResponses bool
+	// MaxTextLength truncates logged text to at most this many bytes, cut at a
+	// rune boundary. Zero means no limit.
+	MaxTextLength int
+}
+
+func (c LogConfig) logger() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return slog.Default()
+}
+
+// text returns s as a log value, redacted or truncated as configured.
+func (c LogConfig) text(s string, redact bool) slog.Value {
+	if redact {
+		return slog.StringValue(fmt.Sprintf("[redacted %d bytes]", len(s)))
+	}
+	if c.MaxTextLength > 0 && len(s) > c.MaxTextLength {
+		cut := c.MaxTextLength
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		return slog.StringValue(s[:cut] + "…")
+	}
+	return slog.StringValue(s)
+}
+
+// LogResponse emits one record describing resp.
+func (c LogConfig) LogResponse(ctx context.Context, resp *genai.GenerateContentResponse) {
+	c.logger().LogAttrs(ctx, slog.LevelInfo, "response", responseAttrs(c, resp)...)
+}
+
+// responseAttrs describes a response as log attributes.
+func responseAttrs(c LogConfig, resp *genai.GenerateContentResponse) []slog.Attr {
+	var attrs []slog.Attr
+	if resp.ResponseID != "" {
+		attrs = append(attrs, slog.String("response_id", resp.ResponseID))
+	}
+	if resp.ModelVersion != "" {
+		attrs = append(attrs, slog.String("model_version", resp.ModelVersion))
+	}
+	var reasons []string
+	var text strings.Builder
+	for _, cand := range resp.Candidates {
+		if cand.FinishReason != "" {
+			reasons = append(reasons, string(cand.FinishReason))
+		}
+		if cand.Content == nil {
+			continue
+		}
+		for _, part := range cand.Content.Parts {
+			text.WriteString(part.Text)
+		}
+	}
+	if len(reasons) > 0 {
+		attrs = append(attrs, slog.String("finish_reason", strings.Join(reasons, ",")))
+	}
+	if u := resp.UsageMetadata; u != nil {
+		attrs = append(attrs, slog.Group("tokens",
+			slog.Int("prompt", int(u.PromptTokenCount)),
+			slog.Int("candidates", int(u.CandidatesTokenCount)),
+			slog.Int("thoughts", int(u.ThoughtsTokenCount)),
+			slog.Int("cached", int(u.CachedContentTokenCount)),
+			slog.Int("total", int(u.TotalTokenCount)),
+		))
+	}
+	attrs = append(attrs, slog.Attr{Key: "text", Value: c.text(text.String(), c.RedactResponses)})
+	return attrs
+}
+
+// LoggingTransport is an http.RoundTripper that emits one structured record
+// per Gemini API call.
+type LoggingTransport struct {
+	// Base is the transport used to send requests. If nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+	// Config sets where records go and what is redacted.
+	Config LogConfig
+}
+
+func (t *LoggingTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c := t.Config
+	op := classifyRequest(req)
+	attrs := []slog.Attr{
+		slog.String("request_id", fmt.Sprintf("%016x", rand.Uint64())),
+		slog.String("operation", string(op)),
+	}
+	if model := modelFromPath(req.URL.Path); model != "" {
+		attrs = append(attrs, slog.String("model", model))
+	}
+	if prompt, ok := promptText(req); ok {
+		attrs = append(attrs, slog.Attr{Key: "prompt", Value: c.text(prompt, c.RedactPrompts)})
+	}
+	start := time.Now()
+	ctx := req.Context()
+
+	resp, err := t.base().RoundTrip(req)
+	if err != nil {
+		attrs = append(attrs, slog.Duration("latency", time.Since(start)), slog.Any("err", err))
+		c.logger().LogAttrs(ctx, slog.LevelError, "genai call failed", attrs...)
+		return nil, err
+	}
+	attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		attrs = append(attrs, slog.Duration("latency", time.Since(start)))
+		c.logger().LogAttrs(ctx, slog.LevelError, "genai call failed", attrs...)
+		return resp, nil
+	}
+
+	switch op {
+	case OpGenerateContentStream:
+		merged := &genai.GenerateContentResponse{}
+		resp.Body = &streamBody{
+			rc: resp.Body,
+			onData: func(data []byte) {
+				var chunk genai.GenerateContentResponse
+				if json.Unmarshal(data, &chunk) == nil {
+					mergeChunk(merged, &chunk)
+				}
+			},
+			onDone: func(err error) {
+				attrs = append(attrs, slog.Duration("latency", time.Since(start)))
+				attrs = append(attrs, responseAttrs(c, merged)...)
+				if err != nil {
+					attrs = append(attrs, slog.Any("err", err))
+					c.logger().LogAttrs(ctx, slog.LevelError, "genai call failed", attrs...)
+					return
+				}
+				c.logger().LogAttrs(ctx, slog.LevelInfo, "genai call", attrs...)
+			},
+		}
+	case OpGenerateContent:
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		attrs = append(attrs, slog.Duration("latency", time.Since(start)))
+		var parsed genai.GenerateContentResponse
+		if err == nil && json.Unmarshal(body, &parsed) == nil {
+			attrs = append(attrs, responseAttrs(c, &parsed)...)
+		}
+		c.logger().LogAttrs(ctx, slog.LevelInfo, "genai call", attrs...)
+	default:
+		attrs = append(attrs, slog.Duration("latency", time.Since(start)))
+		c.logger().LogAttrs(ctx, slog.LevelInfo, "genai call", attrs...)
+	}
+	return resp, nil
+}
+
+// mergeChunk folds one streamed chunk into the running response: text is
+// appended and the latest metadata wins.
+func mergeChunk(dst, chunk *genai.GenerateContentResponse) {
+	if chunk.ResponseID != "" {
+		dst.ResponseID = chunk.ResponseID
+	}
+	if chunk.ModelVersion != "" {
+		dst.ModelVersion = chunk.ModelVersion
+	}
+	if chunk.UsageMetadata != nil {
+		dst.UsageMetadata = chunk.UsageMetadata
+	}
+	for i, cand := range chunk.Candidates {
+		if i >= len(dst.Candidates) {
+			dst.Candidates = append(dst.Candidates, &genai.Candidate{Content: &genai.Content{}})
+		}
+		if cand.FinishReason != "" {
+			dst.Candidates[i].FinishReason = cand.FinishReason
+		}
+		if cand.Content != nil {
+			dst.Candidates[i].Content.Parts = append(dst.Candidates[i].Content.Parts, cand.Content.Parts...)
+		}
+	}
+}
+
+// promptText returns the text parts of a request's contents without
+// consuming the request body.
+func promptText(req *http.Request) (string, bool) {
+	if req.GetBody == nil {
+		return "", false
+	}
+	rc, err := req.GetBody()
+	if err != nil {
+		return "", false
+	}
+	defer rc.Close()
+	var body struct {
+		Contents []*genai.Content `json:"contents"`
+	}
+	if json.NewDecoder(rc).Decode(&body) != nil || len(body.Contents) == 0 {
+		return "", false
+	}
+	var text strings.Builder
+	for _, c := range body.Contents {
+		for _, p := range c.Parts {
+			text.WriteString(p.Text)
+		}
+	}
+	return text.String(), true
+}
+
+func LoggingGenerateContent() (*genai.GenerateContentResponse, error) {
+	// [START logging_generate_content]
+	ctx := context.Background()
+	logConfig := LogConfig{
+		Logger:        slog.New(slog.NewJSONHandler(os.Stderr, nil)),
+		RedactPrompts: true,
+		MaxTextLength: 200,
+	}
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:  os.Getenv("GEMINI_API_KEY"),
+		Backend: genai.BackendGeminiAPI,
+		// Log every attempt the retry layer makes.
+		HTTPClient: &http.Client{Transport: &LoggingTransport{
+			Config: logConfig,
+			Base:   &RetryTransport{Policy: DefaultRetryPolicy()},
+		}},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Each call is logged with its request ID, model, latency, tokens and finish reason.
+	response, err := client.Models.GenerateContent(
+		ctx,
+		"gemini-2.0-flash",
+		genai.Text("Write a story about a magic backpack."),
+		nil,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logConfig.LogResponse(ctx, response)
+	// [END logging_generate_content]
+	return response, err
+}
@@ -0,0 +1,145 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+// captureLogs returns c with its records going to a buffer as JSON.
+func captureLogs(c LogConfig) (LogConfig, *bytes.Buffer) {
+	var buf bytes.Buffer
+	c.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+	return c, &buf
+}
+
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var r map[string]any
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("bad log line %q: %v", line, err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestLoggingTransportGenerateContent(t *testing.T) {
+	c, buf := captureLogs(LogConfig{RedactPrompts: true})
+	client := newTestClientWithConfig(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := textResponse("a secret answer")
+		resp["responseId"] = "resp-1"
+		resp["usageMetadata"] = map[string]any{"promptTokenCount": 5, "candidatesTokenCount": 3, "totalTokenCount": 8}
+		writeJSON(w, http.StatusOK, resp)
+	}), &genai.ClientConfig{HTTPClient: &http.Client{Transport: &LoggingTransport{Config: c}}})
+
+	if _, err := client.Models.GenerateContent(context.Background(), "gemini-2.0-flash", genai.Text("my secret prompt"), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	records := logRecords(t, buf)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	r := records[0]
+	if r["model"] != "gemini-2.0-flash" || r["response_id"] != "resp-1" || r["finish_reason"] != "STOP" {
+		t.Errorf("unexpected record %v", r)
+	}
+	if r["request_id"] == "" || r["latency"] == nil {
+		t.Errorf("record is missing request_id or latency: %v", r)
+	}
+	if tokens, _ := r["tokens"].(map[string]any); tokens["prompt"] != float64(5) {
+		t.Errorf("tokens = %v", r["tokens"])
+	}
+	if strings.Contains(buf.String(), "my secret prompt") {
+		t.Error("prompt was logged despite RedactPrompts")
+	}
+	if r["text"] != "a secret answer" {
+		t.Errorf("text = %v", r["text"])
+	}
+}
+
+func TestLoggingTransportStream(t *testing.T) {
+	c, buf := captureLogs(LogConfig{RedactResponses: true})
+	client := newTestClientWithConfig(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, text := range []string{"Hello, ", "world"} {
+			b, _ := json.Marshal(textResponse(text))
+			fmt.Fprintf(w, "data: %s\n\n", b)
+		}
+	}), &genai.ClientConfig{HTTPClient: &http.Client{Transport: &LoggingTransport{Config: c}}})
+
+	for _, err := range client.Models.GenerateContentStream(context.Background(), "gemini-2.0-flash", genai.Text("hi"), nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records := logRecords(t, buf)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if got := records[0]["text"]; got != "[redacted 12 bytes]" {
+		t.Errorf("text = %v, want redacted", got)
+	}
+	if got := records[0]["prompt"]; got != "hi" {
+		t.Errorf("prompt = %v, want %q", got, "hi")
+	}
+}
+
+func TestLoggingTransportError(t *testing.T) {
+	c, buf := captureLogs(LogConfig{})
+	client := newTestClientWithConfig(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusNotFound, "NOT_FOUND", "gone")
+	}), &genai.ClientConfig{HTTPClient: &http.Client{Transport: &LoggingTransport{Config: c}}})
+
+	client.Models.GenerateContent(context.Background(), "retired-model", genai.Text("hi"), nil)
+
+	records := logRecords(t, buf)
+	if len(records) != 1 || records[0]["level"] != "ERROR" || records[0]["status"] != float64(404) {
+		t.Errorf("records = %v", records)
+	}
+}
+
+func TestLogResponseTruncates(t *testing.T) {
+	c, buf := captureLogs(LogConfig{MaxTextLength: 4})
+	c.LogResponse(context.Background(), &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content: genai.NewContentFromText("abcdefgh", "model"),
+		}},
+	})
+	records := logRecords(t, buf)
+	if len(records) != 1 || records[0]["text"] != "abcd…" {
+		t.Errorf("records = %v", records)
+	}
+
+	// "héé" is 5 bytes; 4 would split the second é.
+	c, buf = captureLogs(LogConfig{MaxTextLength: 4})
+	c.LogResponse(context.Background(), &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content: genai.NewContentFromText("héé", "model"),
+		}},
+	})
+	records = logRecords(t, buf)
+	if len(records) != 1 || records[0]["text"] != "hé…" {
+		t.Errorf("records = %v", records)
+	}
+}
+
+func TestLoggingGenerateContent(t *testing.T) {
+	_, err := LoggingGenerateContent()
+	if err != nil {
+		t.Errorf("LoggingGenerateContent returned an error: %v", err)
+	}
+}
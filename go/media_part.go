@@ -0,0 +1,186 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"google.golang.org/genai"
+)
+
+// DefaultInlineThreshold is the largest total payload PartBuilder sends
+// inline. Requests are limited to 20 MB and inline data grows by a third
+// when base64 encoded, which leaves room for the prompt.
+const DefaultInlineThreshold = 14 << 20
+
+// PartBuilder turns local media into a *genai.Part, sending small payloads
+// inline and uploading larger ones through the File API. The parts built
+// since the last Reset are assumed to go into one request: once their inline
+// payloads add up to InlineThreshold, further media is uploaded.
+type PartBuilder struct {
+	Client *genai.Client
+	// Model, if set, rejects media that model cannot read.
+	Model string
+	// InlineThreshold is the largest total payload, in bytes, sent inline
+	// in one request. Zero means DefaultInlineThreshold.
+	InlineThreshold int
+	// Uploader, if set, deduplicates File API uploads by content.
+	Uploader *FileUploader
+	// Wait configures polling for uploaded files to become active.
+	Wait *WaitOptions
+
+	mu     sync.Mutex
+	inline int
+}
+
+// Reset starts a new request, making the whole InlineThreshold available
+// again.
+func (b *PartBuilder) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inline = 0
+}
+
+// NewRequest returns a builder with b's settings for the parts of another
+// request, with none of the inline threshold used.
+func (b *PartBuilder) NewRequest() *PartBuilder {
+	return &PartBuilder{
+		Client:          b.Client,
+		Model:           b.Model,
+		InlineThreshold: b.InlineThreshold,
+		Uploader:        b.Uploader,
+		Wait:            b.Wait,
+	}
+}
+
+// reserveInline counts n more inline bytes against the threshold. It
+// reports false, counting nothing, if they don't fit.
+func (b *PartBuilder) reserveInline(n int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.inline+n > b.threshold() {
+		return false
+	}
+	b.inline += n
+	return true
+}
+
+func (b *PartBuilder) remainingInline() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return max(b.threshold()-b.inline, 0)
+}
+
+func (b *PartBuilder) threshold() int {
+	if b.InlineThreshold > 0 {
+		return b.InlineThreshold
+	}
+	return DefaultInlineThreshold
+}
+
+// FromPath builds a part from the file at path, detecting its MIME type.
+func (b *PartBuilder) FromPath(ctx context.Context, path string) (*genai.Part, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("media part: %w", err)
+	}
+	defer f.Close()
+	return b.build(ctx, f, filepath.Base(path), "")
+}
+
+// FromReader builds a part from r. If mimeType is empty it is detected from
+// the content. Only as much as still fits inline is buffered.
+func (b *PartBuilder) FromReader(ctx context.Context, r io.Reader, mimeType string) (*genai.Part, error) {
+	return b.build(ctx, r, "", mimeType)
+}
+
+// FromBytes builds a part from data. If mimeType is empty it is detected
+// from the content.
+func (b *PartBuilder) FromBytes(ctx context.Context, data []byte, mimeType string) (*genai.Part, error) {
+	return b.build(ctx, bytes.NewReader(data), "", mimeType)
+}
+
+func (b *PartBuilder) build(ctx context.Context, r io.Reader, name, mimeType string) (*genai.Part, error) {
+	// Read enough to detect the type even when little room is left.
+	budget := b.remainingInline()
+	head, err := io.ReadAll(io.LimitReader(r, int64(max(budget, 512))+1))
+	if err != nil {
+		return nil, fmt.Errorf("media part: %w", err)
+	}
+	if mimeType == "" {
+		mimeType = normalizeMIMEType(DetectMIMEType(name, head))
+	}
+	if b.Model != "" {
+		if allowed := AcceptedMIMETypes(b.Model); !slices.Contains(allowed, mimeType) {
+			return nil, &UnsupportedMIMETypeError{Path: name, MIMEType: mimeType, Model: b.Model, Allowed: allowed}
+		}
+	}
+	if len(head) <= budget && b.reserveInline(len(head)) {
+		return genai.NewPartFromBytes(head, mimeType), nil
+	}
+
+	cfg := &genai.UploadFileConfig{MIMEType: mimeType, DisplayName: name}
+	var f *genai.File
+	if b.Uploader != nil {
+		f, err = b.Uploader.Upload(ctx, io.MultiReader(bytes.NewReader(head), r), cfg)
+	} else {
+		f, err = b.Client.Files.Upload(ctx, io.MultiReader(bytes.NewReader(head), r), cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("media part: %w", err)
+	}
+	if f.State != genai.FileStateActive {
+		if f, err = WaitForFileActive(ctx, b.Client, f.Name, b.Wait); err != nil {
+			return nil, fmt.Errorf("media part: %w", err)
+		}
+	}
+	return genai.NewPartFromURI(f.URI, f.MIMEType), nil
+}
+
+func TextGenMultimodalAutoPart() (*genai.GenerateContentResponse, error) {
+	// [START text_gen_multimodal_auto_part]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Small files are sent inline; anything over the threshold is uploaded
+	// and waited on before the part is returned.
+	builder := &PartBuilder{Client: client, Model: "gemini-2.0-flash"}
+	organ, err := builder.FromPath(ctx, filepath.Join(getMedia(), "organ.jpg"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	cajun, err := builder.FromPath(ctx, filepath.Join(getMedia(), "Cajun_instruments.jpg"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	parts := []*genai.Part{
+		genai.NewPartFromText("What is the difference between both of these instruments?"),
+		organ,
+		cajun,
+	}
+	contents := []*genai.Content{
+		genai.NewContentFromParts(parts, "user"),
+	}
+
+	response, err := client.Models.GenerateContent(ctx, "gemini-2.0-flash", contents, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printResponse(response)
+	// [END text_gen_multimodal_auto_part]
+	return response, err
+}
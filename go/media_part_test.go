@@ -0,0 +1,102 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPartBuilderInline(t *testing.T) {
+	files := newFakeFiles()
+	b := &PartBuilder{Client: newTestClient(t, files), Model: "gemini-2.0-flash"}
+	part, err := b.FromPath(context.Background(), filepath.Join(getMedia(), "organ.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if part.InlineData == nil || part.InlineData.MIMEType != "image/jpeg" || len(part.InlineData.Data) != 383781 {
+		t.Errorf("part = %+v, want inline JPEG", part)
+	}
+	if files.uploadCount() != 0 {
+		t.Error("small file was uploaded")
+	}
+}
+
+func TestPartBuilderUploadsLargePayload(t *testing.T) {
+	files := newFakeFiles()
+	files.ProcessingPolls = 2
+	b := &PartBuilder{
+		Client:          newTestClient(t, files),
+		InlineThreshold: 16,
+		Wait:            &WaitOptions{InitialInterval: time.Millisecond},
+	}
+	data := append([]byte("%PDF-1.4\n"), bytes.Repeat([]byte("x"), 100)...)
+	part, err := b.FromReader(context.Background(), bytes.NewReader(data), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if part.FileData == nil || part.FileData.MIMEType != "application/pdf" {
+		t.Fatalf("part = %+v, want file reference", part)
+	}
+	name := strings.TrimPrefix(part.FileData.FileURI, "https://generativelanguage.googleapis.com/v1beta/")
+	f := files.get(name)
+	if f == nil || *f.SizeBytes != int64(len(data)) {
+		t.Errorf("uploaded file = %+v, want all %d bytes", f, len(data))
+	}
+}
+
+func TestPartBuilderTracksInlineTotal(t *testing.T) {
+	files := newFakeFiles()
+	b := &PartBuilder{Client: newTestClient(t, files), InlineThreshold: 100}
+	ctx := context.Background()
+	data := bytes.Repeat([]byte("x"), 40)
+
+	var kinds []string
+	build := func() {
+		part, err := b.FromBytes(ctx, data, "text/plain")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if part.InlineData != nil {
+			kinds = append(kinds, "inline")
+		} else {
+			kinds = append(kinds, "file")
+		}
+	}
+	build()
+	build()
+	build()
+	b.Reset()
+	build()
+	if want := []string{"inline", "inline", "file", "inline"}; !slices.Equal(kinds, want) {
+		t.Errorf("parts = %v, want %v", kinds, want)
+	}
+	if files.uploadCount() != 1 {
+		t.Errorf("uploads = %d, want 1", files.uploadCount())
+	}
+	build()
+	build()
+	if part, err := b.NewRequest().FromBytes(ctx, data, "text/plain"); err != nil || part.InlineData == nil {
+		t.Errorf("NewRequest part = %+v, %v; want inline", part, err)
+	}
+}
+
+func TestPartBuilderRejectsUnsupported(t *testing.T) {
+	b := &PartBuilder{Client: newTestClient(t, newFakeFiles()), Model: "gemini-2.0-flash"}
+	_, err := b.FromBytes(context.Background(), []byte("GIF89a"), "")
+	var unsupported *UnsupportedMIMETypeError
+	if !errors.As(err, &unsupported) {
+		t.Errorf("err = %v, want UnsupportedMIMETypeError", err)
+	}
+}
+
+func TestTextGenMultimodalAutoPart(t *testing.T) {
+	_, err := TextGenMultimodalAutoPart()
+	if err != nil {
+		t.Errorf("TextGenMultimodalAutoPart returned an error: %v", err)
+	}
+}
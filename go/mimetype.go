@@ -0,0 +1,303 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// GeminiMIMETypes lists the MIME types the multimodal Gemini models accept.
+var GeminiMIMETypes = []string{
+	"application/pdf",
+	"audio/aac", "audio/aiff", "audio/flac", "audio/mp3", "audio/ogg", "audio/wav",
+	"image/heic", "image/heif", "image/jpeg", "image/png", "image/webp",
+	"text/css", "text/csv", "text/html", "text/javascript", "text/md", "text/plain",
+	"text/rtf", "text/x-python", "text/xml",
+	"video/3gpp", "video/avi", "video/mov", "video/mp4", "video/mpeg", "video/mpg",
+	"video/webm", "video/wmv", "video/x-flv",
+}
+
+// mimeAliases maps types reported by sniffing or by the mime package to the
+// name the API accepts.
+var mimeAliases = map[string]string{
+	"application/javascript": "text/javascript",
+	"application/json":       "text/plain",
+	"application/x-python":   "text/x-python",
+	"application/xml":        "text/xml",
+	"application/rtf":        "text/rtf",
+	"audio/mpeg":             "audio/mp3",
+	"audio/wave":             "audio/wav",
+	"audio/x-aiff":           "audio/aiff",
+	"audio/x-flac":           "audio/flac",
+	"audio/x-wav":            "audio/wav",
+	"audio/vnd.wave":         "audio/wav",
+	"image/jpg":              "image/jpeg",
+	"image/pjpeg":            "image/jpeg",
+	"text/markdown":          "text/md",
+	"text/x-markdown":        "text/md",
+	"video/mpeg4":            "video/mp4",
+	"video/quicktime":        "video/mov",
+	"video/x-ms-wmv":         "video/wmv",
+	"video/x-msvideo":        "video/avi",
+}
+
+// AcceptedMIMETypes returns the MIME types model accepts as file input.
+// Embedding models accept text only.
+func AcceptedMIMETypes(model string) []string {
+	if strings.Contains(model, "embedding") {
+		return []string{"text/plain"}
+	}
+	return GeminiMIMETypes
+}
+
+// UnsupportedMIMETypeError reports content the model cannot read.
+type UnsupportedMIMETypeError struct {
+	Path     string
+	MIMEType string
+	Model    string
+	Allowed  []string
+}
+
+func (e *UnsupportedMIMETypeError) Error() string {
+	return fmt.Sprintf("%s: MIME type %q is not supported by %s; allowed types: %s",
+		e.Path, e.MIMEType, e.Model, strings.Join(e.Allowed, ", "))
+}
+
+// magic lists the file signatures checked by DetectMIMEType. The RIFF and
+// ISO-BMFF containers are handled separately.
+var magic = []struct {
+	sig      string
+	mimeType string
+}{
+	{"\xFF\xD8\xFF", "image/jpeg"},
+	{"\x89PNG\r\n\x1A\n", "image/png"},
+	{"GIF87a", "image/gif"},
+	{"GIF89a", "image/gif"},
+	{"%PDF-", "application/pdf"},
+	{"ID3", "audio/mp3"},
+	{"fLaC", "audio/flac"},
+	{"OggS", "audio/ogg"},
+	{"FLV", "video/x-flv"},
+	{"\x1A\x45\xDF\xA3", "video/webm"},
+	{"\x00\x00\x01\xBA", "video/mpeg"},
+	{"\x30\x26\xB2\x75\x8E\x66\xCF\x11", "video/wmv"},
+	{"{\\rtf", "text/rtf"},
+}
+
+// DetectMIMEType identifies content from its first bytes, falling back to
+// the file extension and then to text detection. The result is not
+// normalized; see ResolveMIMEType.
+func DetectMIMEType(path string, head []byte) string {
+	for _, m := range magic {
+		if bytes.HasPrefix(head, []byte(m.sig)) {
+			return m.mimeType
+		}
+	}
+	if len(head) >= 12 {
+		switch {
+		case string(head[:4]) == "RIFF" && string(head[8:12]) == "WAVE":
+			return "audio/wav"
+		case string(head[:4]) == "RIFF" && string(head[8:12]) == "WEBP":
+			return "image/webp"
+		case string(head[:4]) == "RIFF" && string(head[8:12]) == "AVI ":
+			return "video/avi"
+		case string(head[:4]) == "FORM" && (string(head[8:12]) == "AIFF" || string(head[8:12]) == "AIFC"):
+			return "audio/aiff"
+		case string(head[4:8]) == "ftyp" && ftypMIMEType(string(head[8:12])) != "":
+			return ftypMIMEType(string(head[8:12]))
+		}
+	}
+
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return baseMIMEType(t)
+	}
+	if t := mediaTypeByExtension(path); t != "application/octet-stream" {
+		return t
+	}
+	// Bare audio frames have only a weak signature, so they are checked
+	// after the extension: a UTF-16LE byte order mark also starts FF FE.
+	if t := audioFrameMIMEType(head); t != "" {
+		return t
+	}
+	if t := baseMIMEType(http.DetectContentType(head)); strings.HasPrefix(t, "text/") {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// audioFrameMIMEType recognizes a stream that starts with a complete ADTS or
+// MPEG audio frame header. It returns "" for anything else.
+func audioFrameMIMEType(head []byte) string {
+	if len(head) < 4 || head[0] != 0xFF {
+		return ""
+	}
+	switch {
+	case head[1]&0xF6 == 0xF0:
+		// ADTS: layer 0, a defined sampling frequency index and channels.
+		if (head[2]>>2)&0x0F < 13 {
+			return "audio/aac"
+		}
+	case head[1]&0xE0 == 0xE0:
+		// MPEG audio: version, layer, bitrate and sample rate must not be
+		// reserved or free-format.
+		version, layer := (head[1]>>3)&3, (head[1]>>1)&3
+		bitrate, rate := head[2]>>4, (head[2]>>2)&3
+		if version != 1 && layer != 0 && bitrate != 0 && bitrate != 15 && rate != 3 {
+			return "audio/mp3"
+		}
+	}
+	return ""
+}
+
+// ftypMIMEType maps an ISO base media file brand to a MIME type. It returns
+// "" for brands it doesn't know.
+func ftypMIMEType(brand string) string {
+	switch {
+	case brand == "qt  ":
+		return "video/quicktime"
+	case strings.HasPrefix(brand, "3g"):
+		return "video/3gpp"
+	case brand == "avif", brand == "avis":
+		return "image/avif"
+	case brand == "heic", brand == "heix", brand == "heim", brand == "heis":
+		return "image/heic"
+	case brand == "mif1", brand == "msf1":
+		return "image/heif"
+	case brand == "M4A ", brand == "M4B ":
+		return "audio/aac"
+	case strings.HasPrefix(brand, "iso"), strings.HasPrefix(brand, "mp4"),
+		brand == "avc1", brand == "dash", brand == "mmp4", brand == "M4V ", brand == "M4VH", brand == "f4v ":
+		return "video/mp4"
+	}
+	return ""
+}
+
+func baseMIMEType(t string) string {
+	if mt, _, err := mime.ParseMediaType(t); err == nil {
+		return mt
+	}
+	return t
+}
+
+// ResolveMIMEType detects the content type of a file, maps aliases to the
+// names the API uses and checks that model accepts it.
+func ResolveMIMEType(model, path string, head []byte) (string, error) {
+	t := normalizeMIMEType(DetectMIMEType(path, head))
+	allowed := AcceptedMIMETypes(model)
+	if !slices.Contains(allowed, t) {
+		return "", &UnsupportedMIMETypeError{Path: path, MIMEType: t, Model: model, Allowed: allowed}
+	}
+	return t, nil
+}
+
+// normalizeMIMEType maps aliases to the names the API uses. Text formats
+// without a name of their own are sent as text/plain.
+func normalizeMIMEType(t string) string {
+	if alias, ok := mimeAliases[t]; ok {
+		t = alias
+	}
+	if strings.HasPrefix(t, "text/") && !slices.Contains(GeminiMIMETypes, t) {
+		t = "text/plain"
+	}
+	return t
+}
+
+// readHead returns up to the first 512 bytes of the file at path.
+func readHead(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return head[:n], nil
+}
+
+// UploadForModel uploads the file at path after checking that model can read
+// it. The MIME type is config.MIMEType if set, and is otherwise detected from
+// the content.
+func UploadForModel(ctx context.Context, client *genai.Client, model, path string, config *genai.UploadFileConfig) (*genai.File, error) {
+	cfg := genai.UploadFileConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.MIMEType != "" {
+		t := normalizeMIMEType(baseMIMEType(cfg.MIMEType))
+		if allowed := AcceptedMIMETypes(model); !slices.Contains(allowed, t) {
+			return nil, &UnsupportedMIMETypeError{Path: path, MIMEType: t, Model: model, Allowed: allowed}
+		}
+		cfg.MIMEType = t
+	} else {
+		head, err := readHead(path)
+		if err != nil {
+			return nil, fmt.Errorf("upload: %w", err)
+		}
+		t, err := ResolveMIMEType(model, path, head)
+		if err != nil {
+			return nil, err
+		}
+		cfg.MIMEType = t
+	}
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("upload: %w", err)
+	}
+	defer r.Close()
+	f, err := client.Files.Upload(ctx, r, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("upload %s: %w", path, err)
+	}
+	return f, nil
+}
+
+func FilesCreateDetectedMIMEType() (*genai.GenerateContentResponse, error) {
+	// [START files_create_detected_mime_type]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The MIME type is detected from the file content and checked against the
+	// types the model accepts before anything is uploaded.
+	myfile, err := UploadForModel(ctx, client, "gemini-2.0-flash", filepath.Join(getMedia(), "organ.jpg"), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.Info("File uploaded", "name", myfile.Name, "mime_type", myfile.MIMEType)
+
+	parts := []*genai.Part{
+		genai.NewPartFromURI(myfile.URI, myfile.MIMEType),
+		genai.NewPartFromText("Tell me about this instrument"),
+	}
+	contents := []*genai.Content{
+		genai.NewContentFromParts(parts, "user"),
+	}
+
+	response, err := client.Models.GenerateContent(ctx, "gemini-2.0-flash", contents, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printResponse(response)
+	// [END files_create_detected_mime_type]
+	return response, err
+}
@@ -0,0 +1,154 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestDetectMIMETypeMedia(t *testing.T) {
+	for name, want := range map[string]string{
+		"organ.jpg":          "image/jpeg",
+		"test.pdf":           "application/pdf",
+		"Big_Buck_Bunny.mp4": "video/mp4",
+		"poem.txt":           "text/plain",
+	} {
+		path := filepath.Join(getMedia(), name)
+		head, err := readHead(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Content wins over a misleading extension.
+		if got := DetectMIMEType("renamed.bin", head); name != "poem.txt" && got != want {
+			t.Errorf("DetectMIMEType(%s) = %q, want %q", name, got, want)
+		}
+		if got, err := ResolveMIMEType("gemini-2.0-flash", path, head); err != nil || got != want {
+			t.Errorf("ResolveMIMEType(%s) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+}
+
+func TestResolveMIMETypeAliases(t *testing.T) {
+	for _, tc := range []struct {
+		path string
+		head string
+		want string
+	}{
+		{"a.wav", "RIFF\x00\x00\x00\x00WAVEfmt ", "audio/wav"},
+		{"a.mov", "\x00\x00\x00\x14ftypqt  ", "video/mov"},
+		{"a.mp3", "ID3\x04", "audio/mp3"},
+		{"a.heic", "\x00\x00\x00\x18ftypheic", "image/heic"},
+		{"README.md", "# Title", "text/md"},
+		{"main.go", "package main", "text/plain"},
+		{"data.json", `{"a": 1}`, "text/plain"},
+	} {
+		got, err := ResolveMIMEType("gemini-2.0-flash", tc.path, []byte(tc.head))
+		if err != nil || got != tc.want {
+			t.Errorf("ResolveMIMEType(%s) = %q, %v; want %q", tc.path, got, err, tc.want)
+		}
+	}
+}
+
+func TestDetectMIMETypeAudioFrames(t *testing.T) {
+	for _, tc := range []struct {
+		path string
+		head string
+		want string
+	}{
+		{"track", "\xFF\xFB\x90\x64", "audio/mp3"}, // MPEG-1 layer III, 128 kbit/s
+		{"track", "\xFF\xF1\x50\x80", "audio/aac"}, // ADTS, 44.1 kHz
+		{"track", "\xFF\xFB\xF0\x64", ""},          // bad bitrate
+		{"track", "\xFF\xE9\x90\x64", ""},          // reserved layer
+		// A UTF-16LE byte order mark looks like a frame sync.
+		{"notes.txt", "\xFF\xFEH\x00i\x00", "text/plain"},
+		{"table.csv", "\xFF\xFEa\x00,\x00", "text/csv"},
+	} {
+		// An empty want means anything but audio.
+		got := DetectMIMEType(tc.path, []byte(tc.head))
+		if tc.want == "" && strings.HasPrefix(got, "audio/") || tc.want != "" && got != tc.want {
+			t.Errorf("DetectMIMEType(%s, % x) = %q, want %q", tc.path, tc.head, got, tc.want)
+		}
+	}
+}
+
+func TestDetectMIMETypeFtypBrands(t *testing.T) {
+	for _, tc := range []struct {
+		path  string
+		brand string
+		want  string
+	}{
+		{"a.bin", "isom", "video/mp4"},
+		{"a.bin", "mp42", "video/mp4"},
+		{"a.bin", "avif", "image/avif"},
+		{"a.bin", "heic", "image/heic"},
+		{"a.bin", "mif1", "image/heif"},
+		{"a.bin", "M4A ", "audio/aac"},
+		// Unknown brands fall back to the extension.
+		{"a.bin", "zzzz", "application/octet-stream"},
+		{"a.mp4", "zzzz", "video/mp4"},
+	} {
+		head := "\x00\x00\x00\x18ftyp" + tc.brand
+		if got := DetectMIMEType(tc.path, []byte(head)); got != tc.want {
+			t.Errorf("DetectMIMEType(%s, %q) = %q, want %q", tc.path, tc.brand, got, tc.want)
+		}
+	}
+}
+
+func TestResolveMIMETypeRejects(t *testing.T) {
+	_, err := ResolveMIMEType("gemini-2.0-flash", "anim.gif", []byte("GIF89a"))
+	var unsupported *UnsupportedMIMETypeError
+	if !errors.As(err, &unsupported) || unsupported.MIMEType != "image/gif" {
+		t.Fatalf("err = %v, want UnsupportedMIMETypeError for image/gif", err)
+	}
+	if !strings.Contains(err.Error(), "image/png") {
+		t.Errorf("error does not list the allowed types: %v", err)
+	}
+
+	_, err = ResolveMIMEType("text-embedding-004", "organ.jpg", []byte("\xFF\xD8\xFF\xE0"))
+	if !errors.As(err, &unsupported) {
+		t.Errorf("embedding model accepted an image: %v", err)
+	}
+}
+
+func TestUploadForModel(t *testing.T) {
+	files := newFakeFiles()
+	client := newTestClient(t, files)
+	ctx := context.Background()
+
+	f, err := UploadForModel(ctx, client, "gemini-2.0-flash", filepath.Join(getMedia(), "test.pdf"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.MIMEType != "application/pdf" {
+		t.Errorf("MIMEType = %q", f.MIMEType)
+	}
+
+	gif := writeTempFile(t, "anim.gif", "GIF89a")
+	var unsupported *UnsupportedMIMETypeError
+	if _, err := UploadForModel(ctx, client, "gemini-2.0-flash", gif, nil); !errors.As(err, &unsupported) {
+		t.Errorf("err = %v, want UnsupportedMIMETypeError", err)
+	}
+	// An explicit MIME type is used instead of the detected one.
+	notes := writeTempFile(t, "notes.dat", "# Notes")
+	f, err = UploadForModel(ctx, client, "gemini-2.0-flash", notes, &genai.UploadFileConfig{MIMEType: "text/markdown; charset=utf-8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.MIMEType != "text/md" {
+		t.Errorf("MIMEType = %q, want text/md", f.MIMEType)
+	}
+	if files.uploadCount() != 2 {
+		t.Errorf("uploads = %d, want 2", files.uploadCount())
+	}
+}
+
+func TestFilesCreateDetectedMIMEType(t *testing.T) {
+	_, err := FilesCreateDetectedMIMEType()
+	if err != nil {
+		t.Errorf("FilesCreateDetectedMIMEType returned an error: %v", err)
+	}
+}
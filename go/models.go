@@ -2,9 +2,9 @@ package examples
 
 import (
 	"context"
-	"fmt"
-	"os"
 	"log"
+	"log/slog"
+	"os"
 
 	"google.golang.org/genai"
 )
@@ -13,39 +13,37 @@ func ModelsList() error {
 	// [START models_list]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
 	}
 
 
-	// Retrieve the list of models.
-	models, err := client.Models.List(ctx, &genai.ListModelsConfig{})
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	fmt.Println("List of models that support generateContent:")
-	for _, m := range models.Items {
-		for _, action := range m.SupportedActions {
-			if action == "generateContent" {
-				fmt.Println(m.Name)
-				break
-			}
+	// List the models once and group them by supported action.
+	generates, embeds := ModelSupports("generateContent"), ModelSupports("embedContent")
+	var generate, embed []string
+	for m, err := range Filter(client.Models.All(ctx), func(m *genai.Model) bool {
+		return generates(m) || embeds(m)
+	}) {
+		if err != nil {
+			log.Fatal(err)
 		}
-	}
-
-	fmt.Println("\nList of models that support embedContent:")
-	for _, m := range models.Items {
-		for _, action := range m.SupportedActions {
-			if action == "embedContent" {
-				fmt.Println(m.Name)
-				break
-			}
+		if generates(m) {
+			generate = append(generate, m.Name)
+		}
+		if embeds(m) {
+			embed = append(embed, m.Name)
 		}
 	}
+	for _, name := range generate {
+		slog.Info("Model supports generateContent", "name", name)
+	}
+	for _, name := range embed {
+		slog.Info("Model supports embedContent", "name", name)
+	}
 	// [END models_list]
 	return err
 }
@@ -54,8 +52,9 @@ func ModelsGet() error {
 	// [START models_get]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -66,7 +65,13 @@ func ModelsGet() error {
 		log.Fatal(err)
 	}
 
-	fmt.Println(modelInfo)
+	slog.Info("Model",
+		"name", modelInfo.Name,
+		"display_name", modelInfo.DisplayName,
+		"input_token_limit", modelInfo.InputTokenLimit,
+		"output_token_limit", modelInfo.OutputTokenLimit,
+		"supported_actions", modelInfo.SupportedActions,
+	)
 	// [END models_get]
 	return err
 }
@@ -0,0 +1,764 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/genai"
+)
+
+// ErrUnsupportedPDF is returned for PDFs that SplitPDF cannot rewrite, such
+// as those using cross-reference or object streams, or encryption.
+var ErrUnsupportedPDF = errors.New("unsupported PDF")
+
+// PDFChunk is a standalone PDF holding a range of pages of a larger one.
+type PDFChunk struct {
+	// FirstPage and LastPage are 1-based and inclusive.
+	FirstPage, LastPage int
+	Data                []byte
+}
+
+// CountPDFPages returns the number of pages in the document.
+func CountPDFPages(data []byte) (int, error) {
+	doc, err := parsePDF(data)
+	if err != nil {
+		return 0, err
+	}
+	return len(doc.pages), nil
+}
+
+// SplitPDF cuts a PDF into chunks of at most pagesPerChunk pages. Each chunk
+// carries only the objects its pages use. Only documents whose objects are
+// all listed in classic cross-reference tables are supported.
+func SplitPDF(data []byte, pagesPerChunk int) ([]PDFChunk, error) {
+	if pagesPerChunk < 1 {
+		return nil, errors.New("split pdf: pagesPerChunk must be positive")
+	}
+	doc, err := parsePDF(data)
+	if err != nil {
+		return nil, err
+	}
+	var chunks []PDFChunk
+	for first := 1; first <= len(doc.pages); first += pagesPerChunk {
+		last := min(first+pagesPerChunk-1, len(doc.pages))
+		out, err := doc.extract(first, last)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, PDFChunk{FirstPage: first, LastPage: last, Data: out})
+	}
+	return chunks, nil
+}
+
+// pdfDoc is the parsed object table of a PDF.
+type pdfDoc struct {
+	data    []byte
+	version string
+	offsets map[int]int
+	root    int
+	// pages lists page objects in order with their inherited attributes.
+	pages     []pdfPage
+	treeNodes map[int]bool
+}
+
+type pdfPage struct {
+	num       int
+	inherited map[string][]byte
+}
+
+// inheritableKeys are the page attributes that may be set on a Pages node.
+var inheritableKeys = []string{"/Resources", "/MediaBox", "/CropBox", "/Rotate"}
+
+var (
+	pdfRefRE     = regexp.MustCompile(`(\d+)\s+(\d+)\s+R\b`)
+	pdfObjHeadRE = regexp.MustCompile(`^\s*(\d+)\s+(\d+)\s+obj\b`)
+	pdfObjStmRE  = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+)
+
+func parsePDF(data []byte) (*pdfDoc, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, errors.New("pdf: missing %PDF header")
+	}
+	doc := &pdfDoc{data: data, offsets: make(map[int]int), treeNodes: make(map[int]bool)}
+	if eol := bytes.IndexAny(data, "\r\n"); eol > 5 {
+		doc.version = string(data[5:eol])
+	}
+
+	i := bytes.LastIndex(data, []byte("startxref"))
+	if i < 0 {
+		return nil, errors.New("pdf: no startxref")
+	}
+	fields := strings.Fields(string(data[i+len("startxref") : min(i+len("startxref")+32, len(data))]))
+	if len(fields) == 0 {
+		return nil, errors.New("pdf: bad startxref")
+	}
+	off, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("pdf: bad startxref: %w", err)
+	}
+	// Walk the xref chain from the newest section back; newer entries win.
+	for seen := map[int]bool{}; off >= 0 && !seen[off]; {
+		seen[off] = true
+		trailer, err := doc.readXref(off)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := trailer["/Encrypt"]; ok {
+			return nil, fmt.Errorf("pdf: encrypted: %w", ErrUnsupportedPDF)
+		}
+		if _, ok := trailer["/XRefStm"]; ok {
+			return nil, fmt.Errorf("pdf: hybrid cross-reference stream: %w", ErrUnsupportedPDF)
+		}
+		if doc.root == 0 {
+			doc.root = pdfRefNum(trailer["/Root"])
+		}
+		off = -1
+		if prev, ok := trailer["/Prev"]; ok {
+			off, _ = strconv.Atoi(string(bytes.TrimSpace(prev)))
+		}
+	}
+	if doc.root == 0 {
+		return nil, errors.New("pdf: no /Root in trailer")
+	}
+	// Objects inside object streams have no xref table entry of their own.
+	if pdfObjStmRE.Match(data) {
+		return nil, fmt.Errorf("pdf: object streams: %w", ErrUnsupportedPDF)
+	}
+
+	catalog, err := doc.dict(doc.root)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.walkPages(pdfRefNum(catalog["/Pages"]), map[string][]byte{}, 0); err != nil {
+		return nil, err
+	}
+	if len(doc.pages) == 0 {
+		return nil, errors.New("pdf: no pages")
+	}
+	return doc, nil
+}
+
+// readXref parses one classic xref section at off and returns its trailer.
+func (d *pdfDoc) readXref(off int) (map[string][]byte, error) {
+	if off >= len(d.data) {
+		return nil, errors.New("pdf: xref offset out of range")
+	}
+	rest := d.data[off:]
+	if !bytes.HasPrefix(rest, []byte("xref")) {
+		return nil, fmt.Errorf("pdf: cross-reference streams: %w", ErrUnsupportedPDF)
+	}
+	t := bytes.Index(rest, []byte("trailer"))
+	if t < 0 {
+		return nil, errors.New("pdf: xref without trailer")
+	}
+	lines := strings.FieldsFunc(string(rest[len("xref"):t]), func(r rune) bool { return r == '\n' || r == '\r' })
+	for i := 0; i < len(lines); {
+		head := strings.Fields(lines[i])
+		i++
+		if len(head) != 2 {
+			continue
+		}
+		start, err1 := strconv.Atoi(head[0])
+		count, err2 := strconv.Atoi(head[1])
+		if err1 != nil || err2 != nil {
+			return nil, errors.New("pdf: bad xref subsection")
+		}
+		for n := 0; n < count && i < len(lines); n, i = n+1, i+1 {
+			e := strings.Fields(lines[i])
+			if len(e) != 3 || e[2] != "n" {
+				continue
+			}
+			if _, ok := d.offsets[start+n]; ok {
+				continue
+			}
+			if o, err := strconv.Atoi(e[0]); err == nil {
+				d.offsets[start+n] = o
+			}
+		}
+	}
+	p := skipPDFSpace(rest, t+len("trailer"))
+	trailer, _, err := pdfDictEntries(rest, p)
+	return trailer, err
+}
+
+// object returns the value of object num and, for streams, the raw stream
+// section that follows it, from "stream" through "endstream".
+func (d *pdfDoc) object(num int) (value, stream []byte, err error) {
+	off, ok := d.offsets[num]
+	if !ok || off >= len(d.data) {
+		return nil, nil, fmt.Errorf("pdf: object %d not found", num)
+	}
+	b := d.data[off:]
+	m := pdfObjHeadRE.FindSubmatchIndex(b)
+	if m == nil {
+		return nil, nil, fmt.Errorf("pdf: object %d: bad header", num)
+	}
+	start := skipPDFSpace(b, m[1])
+	end, err := pdfValueEnd(b, start)
+	if err != nil {
+		return nil, nil, fmt.Errorf("pdf: object %d: %w", num, err)
+	}
+	value = b[start:end]
+	s := skipPDFSpace(b, end)
+	if !bytes.HasPrefix(b[s:], []byte("stream")) {
+		return value, nil, nil
+	}
+	e := bytes.Index(b[s:], []byte("endstream"))
+	if e < 0 {
+		return nil, nil, fmt.Errorf("pdf: object %d: unterminated stream", num)
+	}
+	return value, b[s : s+e+len("endstream")], nil
+}
+
+func (d *pdfDoc) dict(num int) (map[string][]byte, error) {
+	v, _, err := d.object(num)
+	if err != nil {
+		return nil, err
+	}
+	entries, _, err := pdfDictEntries(v, 0)
+	if err != nil {
+		return nil, fmt.Errorf("pdf: object %d: %w", num, err)
+	}
+	return entries, nil
+}
+
+func (d *pdfDoc) walkPages(num int, inherited map[string][]byte, depth int) error {
+	if num == 0 || depth > 64 {
+		return errors.New("pdf: bad page tree")
+	}
+	node, err := d.dict(num)
+	if err != nil {
+		return err
+	}
+	attrs := make(map[string][]byte, len(inherited))
+	for k, v := range inherited {
+		attrs[k] = v
+	}
+	for _, k := range inheritableKeys {
+		if v, ok := node[k]; ok {
+			attrs[k] = v
+		}
+	}
+	if string(node["/Type"]) == "/Page" {
+		d.pages = append(d.pages, pdfPage{num: num, inherited: attrs})
+		return nil
+	}
+	d.treeNodes[num] = true
+	for _, kid := range pdfRefRE.FindAllSubmatch(node["/Kids"], -1) {
+		n, _ := strconv.Atoi(string(kid[1]))
+		if err := d.walkPages(n, attrs, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extract writes a new PDF with pages first..last (1-based, inclusive).
+func (d *pdfDoc) extract(first, last int) ([]byte, error) {
+	selected := d.pages[first-1 : last]
+	keep := make(map[int]bool)
+	for _, p := range selected {
+		keep[p.num] = true
+	}
+	otherPage := make(map[int]bool)
+	for _, p := range d.pages {
+		if !keep[p.num] {
+			otherPage[p.num] = true
+		}
+	}
+
+	// Rewrite the selected pages: drop /Parent and copy inherited attributes.
+	bodies := make(map[int][]byte)
+	streams := make(map[int][]byte)
+	for _, p := range selected {
+		v, s, err := d.object(p.num)
+		if err != nil {
+			return nil, err
+		}
+		entries, order, err := pdfDictEntriesOrdered(v)
+		if err != nil {
+			return nil, fmt.Errorf("pdf: page %d: %w", p.num, err)
+		}
+		var buf bytes.Buffer
+		buf.WriteString("<<")
+		for _, k := range order {
+			if k == "/Parent" {
+				continue
+			}
+			fmt.Fprintf(&buf, " %s %s", k, entries[k])
+		}
+		for _, k := range inheritableKeys {
+			if _, ok := entries[k]; !ok && p.inherited[k] != nil {
+				fmt.Fprintf(&buf, " %s %s", k, p.inherited[k])
+			}
+		}
+		buf.WriteString(" >>")
+		bodies[p.num], streams[p.num] = buf.Bytes(), s
+	}
+
+	// Copy everything the pages reach, except other pages and tree nodes.
+	order := make([]int, 0, len(selected))
+	for _, p := range selected {
+		order = append(order, p.num)
+	}
+	seen := make(map[int]bool)
+	for _, n := range order {
+		seen[n] = true
+	}
+	for i := 0; i < len(order); i++ {
+		n := order[i]
+		body, ok := bodies[n]
+		if !ok {
+			v, s, err := d.object(n)
+			if err != nil {
+				return nil, err
+			}
+			body, bodies[n], streams[n] = v, v, s
+		}
+		var missing []int
+		mapPDFRefs(body, func(ref int, m []byte) []byte {
+			if seen[ref] || otherPage[ref] || d.treeNodes[ref] {
+				return m
+			}
+			if _, ok := d.offsets[ref]; !ok {
+				missing = append(missing, ref)
+				return m
+			}
+			seen[ref] = true
+			order = append(order, ref)
+			return m
+		})
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("pdf: object %d is not in the cross-reference table: %w", missing[0], ErrUnsupportedPDF)
+		}
+	}
+
+	renum := make(map[int]int, len(order))
+	for i, n := range order {
+		renum[n] = i + 1
+	}
+	pagesNum, catalogNum := len(order)+1, len(order)+2
+	// References to other pages and to the old page tree become null.
+	rewrite := func(b []byte) []byte {
+		return mapPDFRefs(b, func(ref int, _ []byte) []byte {
+			if n, ok := renum[ref]; ok {
+				return []byte(strconv.Itoa(n) + " 0 R")
+			}
+			return []byte("null")
+		})
+	}
+
+	var out bytes.Buffer
+	version := d.version
+	if version == "" {
+		version = "1.4"
+	}
+	fmt.Fprintf(&out, "%%PDF-%s\n%%\xE2\xE3\xCF\xD3\n", version)
+	offsets := make([]int, catalogNum+1)
+	for _, n := range order {
+		offsets[renum[n]] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n", renum[n])
+		body := rewrite(bodies[n])
+		if keep[n] {
+			// The new Pages node is not in renum, so add /Parent after rewriting.
+			body = append(bytes.TrimSuffix(body, []byte(" >>")), fmt.Sprintf(" /Parent %d 0 R >>", pagesNum)...)
+		}
+		out.Write(body)
+		if s := streams[n]; s != nil {
+			out.WriteString("\n")
+			out.Write(s)
+		}
+		out.WriteString("\nendobj\n")
+	}
+	var kids []string
+	for _, p := range selected {
+		kids = append(kids, fmt.Sprintf("%d 0 R", renum[p.num]))
+	}
+	offsets[pagesNum] = out.Len()
+	fmt.Fprintf(&out, "%d 0 obj\n<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", pagesNum, strings.Join(kids, " "), len(kids))
+	offsets[catalogNum] = out.Len()
+	fmt.Fprintf(&out, "%d 0 obj\n<< /Type /Catalog /Pages %d 0 R >>\nendobj\n", catalogNum, pagesNum)
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", catalogNum+1)
+	for _, o := range offsets[1:] {
+		fmt.Fprintf(&out, "%010d 00000 n \n", o)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", catalogNum+1, catalogNum, xref)
+	return out.Bytes(), nil
+}
+
+// mapPDFRefs replaces every indirect reference in b with f's result for it,
+// leaving string literals untouched.
+func mapPDFRefs(b []byte, f func(num int, ref []byte) []byte) []byte {
+	replace := func(seg []byte) []byte {
+		return pdfRefRE.ReplaceAllFunc(seg, func(m []byte) []byte {
+			num, _ := strconv.Atoi(string(pdfRefRE.FindSubmatch(m)[1]))
+			return f(num, m)
+		})
+	}
+	var out []byte
+	start := 0
+	for i := 0; i < len(b); {
+		switch {
+		case bytes.HasPrefix(b[i:], []byte("<<")):
+			i += 2
+		case b[i] == '(' || b[i] == '<':
+			end, err := pdfValueEnd(b, i)
+			if err != nil {
+				end = len(b)
+			}
+			out = append(out, replace(b[start:i])...)
+			out = append(out, b[i:end]...)
+			i, start = end, end
+		default:
+			i++
+		}
+	}
+	return append(out, replace(b[start:])...)
+}
+
+// pdfRefNum returns the object number of an indirect reference such as "3 0 R".
+func pdfRefNum(v []byte) int {
+	m := pdfRefRE.FindSubmatch(v)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(string(m[1]))
+	return n
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == 0
+}
+
+func isPDFDelim(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// skipPDFSpace skips whitespace and comments.
+func skipPDFSpace(b []byte, i int) int {
+	for i < len(b) {
+		switch {
+		case isPDFSpace(b[i]):
+			i++
+		case b[i] == '%':
+			for i < len(b) && b[i] != '\n' && b[i] != '\r' {
+				i++
+			}
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// pdfValueEnd returns the index just past the value starting at b[i]. An
+// indirect reference "n g R" counts as one value.
+func pdfValueEnd(b []byte, i int) (int, error) {
+	if i >= len(b) {
+		return 0, errors.New("unexpected end of data")
+	}
+	switch {
+	case bytes.HasPrefix(b[i:], []byte("<<")):
+		for i += 2; ; {
+			i = skipPDFSpace(b, i)
+			if i >= len(b) {
+				return 0, errors.New("unterminated dictionary")
+			}
+			if bytes.HasPrefix(b[i:], []byte(">>")) {
+				return i + 2, nil
+			}
+			end, err := pdfValueEnd(b, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+		}
+	case b[i] == '[':
+		for i++; ; {
+			i = skipPDFSpace(b, i)
+			if i >= len(b) {
+				return 0, errors.New("unterminated array")
+			}
+			if b[i] == ']' {
+				return i + 1, nil
+			}
+			end, err := pdfValueEnd(b, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+		}
+	case b[i] == '<':
+		e := bytes.IndexByte(b[i:], '>')
+		if e < 0 {
+			return 0, errors.New("unterminated hex string")
+		}
+		return i + e + 1, nil
+	case b[i] == '(':
+		depth := 0
+		for ; i < len(b); i++ {
+			switch b[i] {
+			case '\\':
+				i++
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					return i + 1, nil
+				}
+			}
+		}
+		return 0, errors.New("unterminated string")
+	}
+	j := i
+	if b[j] == '/' {
+		j++
+	}
+	for j < len(b) && !isPDFSpace(b[j]) && !isPDFDelim(b[j]) {
+		j++
+	}
+	if j == i {
+		return 0, fmt.Errorf("unexpected %q", b[i])
+	}
+	if loc := pdfRefRE.FindIndex(b[i:min(len(b), i+40)]); loc != nil && loc[0] == 0 {
+		return i + loc[1], nil
+	}
+	return j, nil
+}
+
+// pdfDictEntries parses the dictionary starting at b[i].
+func pdfDictEntries(b []byte, i int) (map[string][]byte, int, error) {
+	entries, _, end, err := parsePDFDict(b, i)
+	return entries, end, err
+}
+
+// pdfDictEntriesOrdered parses a dictionary value and keeps its key order.
+func pdfDictEntriesOrdered(b []byte) (map[string][]byte, []string, error) {
+	entries, order, _, err := parsePDFDict(b, 0)
+	return entries, order, err
+}
+
+func parsePDFDict(b []byte, i int) (map[string][]byte, []string, int, error) {
+	i = skipPDFSpace(b, i)
+	if !bytes.HasPrefix(b[i:], []byte("<<")) {
+		return nil, nil, 0, errors.New("not a dictionary")
+	}
+	entries := make(map[string][]byte)
+	var order []string
+	for i += 2; ; {
+		i = skipPDFSpace(b, i)
+		if i >= len(b) {
+			return nil, nil, 0, errors.New("unterminated dictionary")
+		}
+		if bytes.HasPrefix(b[i:], []byte(">>")) {
+			return entries, order, i + 2, nil
+		}
+		if b[i] != '/' {
+			return nil, nil, 0, fmt.Errorf("dictionary key is not a name at %d", i)
+		}
+		kEnd, err := pdfValueEnd(b, i)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		key := string(b[i:kEnd])
+		vStart := skipPDFSpace(b, kEnd)
+		vEnd, err := pdfValueEnd(b, vStart)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if _, dup := entries[key]; !dup {
+			order = append(order, key)
+		}
+		entries[key] = b[vStart:vEnd]
+		i = vEnd
+	}
+}
+
+// PDFChunkAnswer is the model's answer for one range of pages.
+type PDFChunkAnswer struct {
+	FirstPage, LastPage int
+	Text                string
+}
+
+// PDFAnswer is the merged result of prompting every chunk of a PDF.
+type PDFAnswer struct {
+	Chunks []PDFChunkAnswer
+	Text   string
+}
+
+// PDFPrompter runs a prompt over a PDF one page range at a time.
+type PDFPrompter struct {
+	Client *genai.Client
+	Model  string
+	// PagesPerChunk is the size of each range. Zero means 10.
+	PagesPerChunk int
+	// Concurrency is the number of chunks prompted at once. Zero means 4.
+	Concurrency int
+	// MergePrompt, if set, asks the model to combine the chunk answers into
+	// one. Otherwise the answers are joined under page headings.
+	MergePrompt string
+	// Parts turns each chunk into a part; by default chunks are uploaded
+	// through the File API. Uploaded chunks are deleted when Prompt returns.
+	Parts *PartBuilder
+}
+
+// Prompt splits pdf, sends prompt with every chunk in parallel and merges
+// the answers in page order.
+func (p *PDFPrompter) Prompt(ctx context.Context, pdf []byte, prompt string) (*PDFAnswer, error) {
+	size := p.PagesPerChunk
+	if size == 0 {
+		size = 10
+	}
+	chunks, err := SplitPDF(pdf, size)
+	if err != nil {
+		return nil, err
+	}
+	parts := p.Parts
+	if parts == nil {
+		// A threshold of one byte sends every chunk through the File API.
+		parts = &PartBuilder{Client: p.Client, InlineThreshold: 1}
+	}
+	n := p.Concurrency
+	if n <= 0 {
+		n = 4
+	}
+
+	var (
+		mu       sync.Mutex
+		uploaded []string
+	)
+	defer func() {
+		// Delete even if ctx was cancelled.
+		p.deleteChunks(context.WithoutCancel(ctx), uploaded)
+	}()
+	addUpload := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		uploaded = append(uploaded, name)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	answers := make([]PDFChunkAnswer, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i, c := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			text, err := p.promptChunk(ctx, parts, c, prompt, addUpload)
+			if err != nil {
+				errs[i] = fmt.Errorf("pages %d-%d: %w", c.FirstPage, c.LastPage, err)
+				cancel()
+				return
+			}
+			answers[i] = PDFChunkAnswer{FirstPage: c.FirstPage, LastPage: c.LastPage, Text: text}
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	res := &PDFAnswer{Chunks: answers}
+	var merged strings.Builder
+	for _, a := range answers {
+		fmt.Fprintf(&merged, "Pages %d-%d:\n%s\n\n", a.FirstPage, a.LastPage, strings.TrimSpace(a.Text))
+	}
+	res.Text = strings.TrimSpace(merged.String())
+	if p.MergePrompt == "" || len(answers) == 1 {
+		if len(answers) == 1 {
+			res.Text = answers[0].Text
+		}
+		return res, nil
+	}
+	resp, err := p.Client.Models.GenerateContent(ctx, p.Model, genai.Text(p.MergePrompt+"\n\n"+res.Text), nil)
+	if err != nil {
+		return nil, fmt.Errorf("merge: %w", err)
+	}
+	res.Text = resp.Text()
+	return res, nil
+}
+
+// promptChunk asks prompt about one chunk. The names of files uploaded for it
+// are passed to uploaded.
+func (p *PDFPrompter) promptChunk(ctx context.Context, parts *PartBuilder, c PDFChunk, prompt string, uploaded func(string)) (string, error) {
+	part, err := parts.NewRequest().FromBytes(ctx, c.Data, "application/pdf")
+	if err != nil {
+		return "", err
+	}
+	if part.FileData != nil {
+		if i := strings.LastIndex(part.FileData.FileURI, "/files/"); i >= 0 {
+			uploaded(part.FileData.FileURI[i+1:])
+		}
+	}
+	contents := []*genai.Content{
+		genai.NewContentFromParts([]*genai.Part{part, genai.NewPartFromText(prompt)}, "user"),
+	}
+	resp, err := p.Client.Models.GenerateContent(ctx, p.Model, contents, nil)
+	if err != nil {
+		return "", err
+	}
+	return resp.Text(), nil
+}
+
+// deleteChunks deletes uploaded chunk files, logging failures.
+func (p *PDFPrompter) deleteChunks(ctx context.Context, names []string) {
+	for _, name := range names {
+		if _, err := p.Client.Files.Delete(ctx, name, nil); err != nil {
+			slog.Warn("Could not delete PDF chunk", "name", name, "err", err)
+		}
+	}
+}
+
+func TextGenMultimodalPdfChunked() (*PDFAnswer, error) {
+	// [START text_gen_multimodal_pdf_chunked]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	pdf, err := os.ReadFile(filepath.Join(getMedia(), "test.pdf"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Summarize one page at a time, then ask the model to combine the
+	// per-page summaries.
+	prompter := &PDFPrompter{
+		Client:        client,
+		Model:         "gemini-2.0-flash",
+		PagesPerChunk: 1,
+		MergePrompt:   "Combine these per-page summaries into one summary of the document.",
+	}
+	answer, err := prompter.Prompt(ctx, pdf, "Give me a summary of these pages.")
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, c := range answer.Chunks {
+		slog.Info("Chunk answer", "first_page", c.FirstPage, "last_page", c.LastPage, "text", c.Text)
+	}
+	slog.Info("Answer", "text", answer.Text)
+	// [END text_gen_multimodal_pdf_chunked]
+	return answer, err
+}
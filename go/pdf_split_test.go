@@ -0,0 +1,156 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+var pageObjectRE = regexp.MustCompile(`/Type\s*/Page[^s]`)
+
+func TestSplitPDF(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(getMedia(), "test.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := SplitPDF(data, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2", len(chunks))
+	}
+	for i, c := range chunks {
+		if c.FirstPage != i+1 || c.LastPage != i+1 {
+			t.Errorf("chunk %d covers pages %d-%d", i, c.FirstPage, c.LastPage)
+		}
+		n, err := CountPDFPages(c.Data)
+		if err != nil || n != 1 {
+			t.Errorf("chunk %d: CountPDFPages = %d, %v; want 1", i, n, err)
+		}
+		if got := len(pageObjectRE.FindAll(c.Data, -1)); got != 1 {
+			t.Errorf("chunk %d has %d page objects, want 1", i, got)
+		}
+		if strings.Contains(string(c.Data), " null") && !strings.Contains(string(data), " null") {
+			t.Errorf("chunk %d has dangling references", i)
+		}
+	}
+
+	whole, err := SplitPDF(data, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := CountPDFPages(whole[0].Data); len(whole) != 1 || n != 2 {
+		t.Errorf("got %d chunks with %d pages, want 1 chunk with 2", len(whole), n)
+	}
+}
+
+func TestSplitPDFUnsupported(t *testing.T) {
+	data := []byte("%PDF-1.5\n1 0 obj\n<< /Type /XRef >>\nstream\nendstream\nendobj\nstartxref\n9\n%%EOF\n")
+	if _, err := SplitPDF(data, 1); !errors.Is(err, ErrUnsupportedPDF) {
+		t.Errorf("err = %v, want ErrUnsupportedPDF", err)
+	}
+	if _, err := SplitPDF([]byte("not a pdf"), 1); err == nil {
+		t.Error("SplitPDF accepted a non-PDF")
+	}
+
+	orig, err := os.ReadFile(filepath.Join(getMedia(), "test.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hybrid := bytes.Replace(orig, []byte("trailer\n<<"), []byte("trailer\n<< /XRefStm 0"), 1)
+	if bytes.Equal(hybrid, orig) {
+		t.Fatal("test.pdf has no trailer to patch")
+	}
+	if _, err := SplitPDF(hybrid, 1); !errors.Is(err, ErrUnsupportedPDF) {
+		t.Errorf("hybrid xref: err = %v, want ErrUnsupportedPDF", err)
+	}
+	objStm := append(bytes.Clone(orig), "\n99 0 obj\n<< /Type /ObjStm /N 1 /First 4 >>\nstream\nendstream\nendobj\n"...)
+	if _, err := SplitPDF(objStm, 1); !errors.Is(err, ErrUnsupportedPDF) {
+		t.Errorf("object streams: err = %v, want ErrUnsupportedPDF", err)
+	}
+}
+
+func TestMapPDFRefsSkipsStrings(t *testing.T) {
+	in := []byte(`<< /A 3 0 R /T (see 3 0 R \) 3 0 R) /H <3020> /B [3 0 R] >>`)
+	got := mapPDFRefs(in, func(num int, _ []byte) []byte { return []byte("7 0 R") })
+	want := `<< /A 7 0 R /T (see 3 0 R \) 3 0 R) /H <3020> /B [7 0 R] >>`
+	if string(got) != want {
+		t.Errorf("mapPDFRefs = %s, want %s", got, want)
+	}
+}
+
+func TestPDFPrompter(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(getMedia(), "test.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := newFakeFiles()
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.Handle("/", files)
+	mux.HandleFunc("POST /v1beta/models/{action}", func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var req struct {
+			Contents []struct {
+				Parts []struct {
+					Text     string `json:"text"`
+					FileData *struct {
+						FileURI string `json:"fileUri"`
+					} `json:"fileData"`
+				} `json:"parts"`
+			} `json:"contents"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		parts := req.Contents[0].Parts
+		if parts[0].FileData == nil {
+			writeJSON(w, http.StatusOK, textResponse("merged"))
+			return
+		}
+		name := strings.TrimPrefix(parts[0].FileData.FileURI, "https://generativelanguage.googleapis.com/v1beta/")
+		if f := files.get(name); f == nil || *f.SizeBytes >= int64(len(data)) {
+			writeAPIError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "bad chunk "+name)
+			return
+		}
+		writeJSON(w, http.StatusOK, textResponse("p"))
+	})
+
+	p := &PDFPrompter{Client: newTestClient(t, mux), Model: "gemini-2.0-flash", PagesPerChunk: 1}
+	answer, err := p.Prompt(context.Background(), data, "Summarize.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(answer.Chunks) != 2 || answer.Chunks[1].FirstPage != 2 || answer.Chunks[1].Text != "p" {
+		t.Errorf("chunks = %+v", answer.Chunks)
+	}
+	if want := "Pages 1-1:\np\n\nPages 2-2:\np"; answer.Text != want {
+		t.Errorf("Text = %q, want %q", answer.Text, want)
+	}
+	if files.uploadCount() != 2 {
+		t.Errorf("uploaded %d chunks, want 2", files.uploadCount())
+	}
+	if n := len(files.order); n != 0 {
+		t.Errorf("%d chunk files left after Prompt", n)
+	}
+
+	p.MergePrompt = "Combine."
+	answer, err = p.Prompt(context.Background(), data, "Summarize.")
+	if err != nil || answer.Text != "merged" || calls.Load() != 5 {
+		t.Errorf("Text = %q, calls = %d, err = %v; want merged after 5 calls", answer.Text, calls.Load(), err)
+	}
+}
+
+func TestTextGenMultimodalPdfChunked(t *testing.T) {
+	_, err := TextGenMultimodalPdfChunked()
+	if err != nil {
+		t.Errorf("TextGenMultimodalPdfChunked returned an error: %v", err)
+	}
+}
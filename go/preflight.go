@@ -0,0 +1,193 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"google.golang.org/genai"
+)
+
+var (
+	// ErrContextWindowExceeded is returned when a prompt is larger than the
+	// model's input token limit.
+	ErrContextWindowExceeded = errors.New("request exceeds the model's context window")
+	// ErrOverBudget is returned when the worst-case cost of a request is above
+	// the configured budget.
+	ErrOverBudget = errors.New("request exceeds the cost budget")
+)
+
+// PreflightEstimate describes a request before it is sent.
+type PreflightEstimate struct {
+	Model            string
+	InputTokens      int
+	InputTokenLimit  int
+	MaxOutputTokens  int
+	OutputTokenLimit int
+	// Cost is the worst-case price in USD: the full prompt plus MaxOutputTokens
+	// of output. It is zero when the model is not in the rate table.
+	Cost     float64
+	Warnings []string
+}
+
+// Preflight checks requests against the model's token limits and a cost
+// budget before they are sent. It is safe for concurrent use.
+type Preflight struct {
+	Client *genai.Client
+	Rates  RateTable
+	// Budget is the most a single request may cost in USD. Zero means no budget.
+	Budget float64
+	// WarnFraction adds a warning when the prompt uses more than this fraction
+	// of the input token limit. Zero means 0.9.
+	WarnFraction float64
+
+	mu     sync.Mutex
+	models map[string]*genai.Model
+}
+
+// NewPreflight returns a Preflight that prices requests with rates and
+// refuses any request whose worst-case cost is above budget.
+func NewPreflight(client *genai.Client, rates RateTable, budget float64) *Preflight {
+	return &Preflight{Client: client, Rates: rates, Budget: budget}
+}
+
+// modelInfo fetches the model's limits once and caches them.
+func (p *Preflight) modelInfo(ctx context.Context, model string) (*genai.Model, error) {
+	p.mu.Lock()
+	m, ok := p.models[model]
+	p.mu.Unlock()
+	if ok {
+		return m, nil
+	}
+	m, err := p.Client.Models.Get(ctx, model, nil)
+	if err != nil {
+		return nil, fmt.Errorf("preflight: get model %s: %w", model, err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.models == nil {
+		p.models = make(map[string]*genai.Model)
+	}
+	p.models[model] = m
+	return m, nil
+}
+
+// Check counts the tokens in contents and compares them with the model's
+// limits and the budget. The estimate is returned even when the request is
+// refused, so callers can report why.
+func (p *Preflight) Check(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*PreflightEstimate, error) {
+	info, err := p.modelInfo(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	count, err := p.Client.Models.CountTokens(ctx, model, contents, nil)
+	if err != nil {
+		return nil, fmt.Errorf("preflight: count tokens: %w", err)
+	}
+
+	est := &PreflightEstimate{
+		Model:            model,
+		InputTokens:      int(count.TotalTokens),
+		InputTokenLimit:  int(info.InputTokenLimit),
+		MaxOutputTokens:  int(info.OutputTokenLimit),
+		OutputTokenLimit: int(info.OutputTokenLimit),
+	}
+	if config != nil && config.MaxOutputTokens > 0 {
+		est.MaxOutputTokens = int(config.MaxOutputTokens)
+		if est.OutputTokenLimit > 0 && est.MaxOutputTokens > est.OutputTokenLimit {
+			est.Warnings = append(est.Warnings, fmt.Sprintf(
+				"max output tokens %d is above the model limit of %d", est.MaxOutputTokens, est.OutputTokenLimit))
+		}
+	}
+	if rate, ok := p.Rates.Lookup(model); ok {
+		est.Cost = rate.Cost(est.InputTokens, 0, est.MaxOutputTokens)
+	} else {
+		est.Warnings = append(est.Warnings, fmt.Sprintf("no price for model %s", model))
+	}
+
+	if est.InputTokenLimit > 0 && est.InputTokens > est.InputTokenLimit {
+		return est, fmt.Errorf("preflight: %d input tokens, limit %d: %w",
+			est.InputTokens, est.InputTokenLimit, ErrContextWindowExceeded)
+	}
+	frac := p.WarnFraction
+	if frac == 0 {
+		frac = 0.9
+	}
+	if est.InputTokenLimit > 0 && float64(est.InputTokens) > frac*float64(est.InputTokenLimit) {
+		est.Warnings = append(est.Warnings, fmt.Sprintf(
+			"prompt uses %d of %d input tokens", est.InputTokens, est.InputTokenLimit))
+	}
+	if p.Budget > 0 && est.Cost > p.Budget {
+		return est, fmt.Errorf("preflight: estimated $%.6f, budget $%.6f: %w", est.Cost, p.Budget, ErrOverBudget)
+	}
+	return est, nil
+}
+
+// GenerateContent runs Check and sends the request only if it passes.
+func (p *Preflight) GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, *PreflightEstimate, error) {
+	est, err := p.Check(ctx, model, contents, config)
+	if err != nil {
+		return nil, est, err
+	}
+	resp, err := p.Client.Models.GenerateContent(ctx, model, contents, config)
+	return resp, est, err
+}
+
+func TokensPreflightCheck() (*genai.GenerateContentResponse, error) {
+	// [START tokens_preflight_check]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := client.Files.UploadFromPath(
+		ctx,
+		filepath.Join(getMedia(), "test.pdf"),
+		&genai.UploadFileConfig{
+			MIMEType: "application/pdf",
+		},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	parts := []*genai.Part{
+		genai.NewPartFromText("Give me a summary of this document."),
+		genai.NewPartFromURI(file.URI, file.MIMEType),
+	}
+	contents := []*genai.Content{
+		genai.NewContentFromParts(parts, "user"),
+	}
+
+	// Refuse anything that would not fit the context window or could cost
+	// more than one cent.
+	preflight := NewPreflight(client, ExampleRates, 0.01)
+	config := &genai.GenerateContentConfig{MaxOutputTokens: 1024}
+	response, estimate, err := preflight.GenerateContent(ctx, "gemini-2.0-flash", contents, config)
+	if estimate != nil {
+		slog.Info("Preflight",
+			"input_tokens", estimate.InputTokens,
+			"input_token_limit", estimate.InputTokenLimit,
+			"max_output_tokens", estimate.MaxOutputTokens,
+			"max_cost_usd", estimate.Cost,
+		)
+		for _, w := range estimate.Warnings {
+			slog.Warn(w)
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	printResponse(response)
+	// [END tokens_preflight_check]
+	return response, err
+}
@@ -0,0 +1,91 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+// preflightServer serves model limits, a fixed token count and a canned
+// response, counting the generateContent calls it receives.
+func preflightServer(t *testing.T, tokens int, generated *atomic.Int32) *genai.Client {
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, ":countTokens"):
+			writeJSON(w, http.StatusOK, map[string]any{"totalTokens": tokens})
+		case strings.HasSuffix(r.URL.Path, ":generateContent"):
+			generated.Add(1)
+			writeJSON(w, http.StatusOK, textResponse("ok"))
+		default:
+			writeJSON(w, http.StatusOK, map[string]any{
+				"name":             "models/gemini-2.0-flash",
+				"inputTokenLimit":  1000,
+				"outputTokenLimit": 100,
+			})
+		}
+	}))
+}
+
+func TestPreflightAllows(t *testing.T) {
+	var generated atomic.Int32
+	p := NewPreflight(preflightServer(t, 950, &generated), ExampleRates, 1)
+	resp, est, err := p.GenerateContent(context.Background(), "gemini-2.0-flash", genai.Text("hi"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text() != "ok" || generated.Load() != 1 {
+		t.Errorf("request was not sent")
+	}
+	if est.InputTokens != 950 || est.MaxOutputTokens != 100 {
+		t.Errorf("estimate = %+v", est)
+	}
+	if want := (950*0.10 + 100*0.40) / 1e6; !almostEqual(est.Cost, want) {
+		t.Errorf("Cost = %v, want %v", est.Cost, want)
+	}
+	if len(est.Warnings) != 1 || !strings.Contains(est.Warnings[0], "950 of 1000") {
+		t.Errorf("Warnings = %q", est.Warnings)
+	}
+}
+
+func TestPreflightRefusesContextWindow(t *testing.T) {
+	var generated atomic.Int32
+	p := NewPreflight(preflightServer(t, 1001, &generated), ExampleRates, 0)
+	_, est, err := p.GenerateContent(context.Background(), "gemini-2.0-flash", genai.Text("hi"), nil)
+	if !errors.Is(err, ErrContextWindowExceeded) {
+		t.Fatalf("err = %v, want ErrContextWindowExceeded", err)
+	}
+	if est == nil || est.InputTokens != 1001 {
+		t.Errorf("estimate = %+v", est)
+	}
+	if generated.Load() != 0 {
+		t.Error("request was sent despite exceeding the context window")
+	}
+}
+
+func TestPreflightRefusesBudget(t *testing.T) {
+	var generated atomic.Int32
+	p := NewPreflight(preflightServer(t, 500, &generated), ExampleRates, 1e-6)
+	config := &genai.GenerateContentConfig{MaxOutputTokens: 500}
+	_, est, err := p.GenerateContent(context.Background(), "gemini-2.0-flash", genai.Text("hi"), config)
+	if !errors.Is(err, ErrOverBudget) {
+		t.Fatalf("err = %v, want ErrOverBudget", err)
+	}
+	if est.MaxOutputTokens != 500 || len(est.Warnings) != 1 {
+		t.Errorf("estimate = %+v", est)
+	}
+	if generated.Load() != 0 {
+		t.Error("request was sent despite exceeding the budget")
+	}
+}
+
+func TestTokensPreflightCheck(t *testing.T) {
+	_, err := TokensPreflightCheck()
+	if err != nil {
+		t.Errorf("TokensPreflightCheck returned an error: %v", err)
+	}
+}
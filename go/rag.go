@@ -0,0 +1,227 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/genai"
+)
+
+// RAG answers questions from local documents. Documents are split into
+// chunks and embedded into a VectorIndex; each question is answered from the
+// chunks closest to it, with citations.
+type RAG struct {
+	Client *genai.Client
+	// Model generates the answers.
+	Model string
+	// EmbedModel embeds chunks and questions. Empty means text-embedding-004.
+	EmbedModel string
+	// Index holds the embedded chunks. Nil means a brute-force cosine index,
+	// created when the first document is added.
+	Index *VectorIndex
+	// Chunker splits documents. Nil means paragraphs of up to 512 tokens,
+	// estimated offline.
+	Chunker *TextChunker
+	// TopK is the number of chunks retrieved for a question. Zero means 5.
+	TopK int
+
+	mu sync.Mutex
+}
+
+// NewRAG returns a RAG that answers with model.
+func NewRAG(client *genai.Client, model string) *RAG {
+	return &RAG{Client: client, Model: model}
+}
+
+// RAGAnswer is a generated answer and the chunks behind it.
+type RAGAnswer struct {
+	Text string
+	// Sources are the IDs of the retrieved chunks the answer cites, in order
+	// of first citation.
+	Sources []string
+	// Retrieved are the chunks given to the model, closest first.
+	Retrieved []SearchResult
+	Response  *genai.GenerateContentResponse
+}
+
+func (r *RAG) embedModel() string {
+	if r.EmbedModel != "" {
+		return r.EmbedModel
+	}
+	return "text-embedding-004"
+}
+
+func (r *RAG) topK() int {
+	if r.TopK > 0 {
+		return r.TopK
+	}
+	return 5
+}
+
+// AddDocument splits text into chunks, embeds them and adds them to the
+// index under their chunk IDs, which it returns. If any chunk ID is already
+// in the index, such as when a document is added twice, no chunk is added.
+func (r *RAG) AddDocument(ctx context.Context, source, text string) ([]string, error) {
+	chunker := r.Chunker
+	if chunker == nil {
+		chunker = &TextChunker{Strategy: ParagraphChunks}
+	}
+	chunks, err := chunker.Split(ctx, source, text)
+	if err != nil {
+		return nil, fmt.Errorf("rag: %w", err)
+	}
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = c.Text
+	}
+	vectors, err := EmbedTexts(ctx, r.Client, r.embedModel(), texts,
+		&genai.EmbedContentConfig{TaskType: "RETRIEVAL_DOCUMENT", Title: source})
+	if err != nil {
+		return nil, fmt.Errorf("rag: %w", err)
+	}
+	ids := make([]string, len(chunks))
+	records := make([]VectorRecord, len(chunks))
+	for i, c := range chunks {
+		ids[i] = c.ID
+		metadata := map[string]string{"source": source, "text": c.Text}
+		if len(c.Headings) > 0 {
+			metadata["headings"] = strings.Join(c.Headings, " > ")
+		}
+		records[i] = VectorRecord{ID: c.ID, Vector: vectors[i], Metadata: metadata}
+	}
+	// Add the chunks together so a conflicting ID leaves the index as it was.
+	if err := r.index(len(vectors[0])).AddBatch(records); err != nil {
+		return nil, fmt.Errorf("rag: %w", err)
+	}
+	return ids, nil
+}
+
+// AddFile adds the text file at path, with its base name as the source.
+func (r *RAG) AddFile(ctx context.Context, path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("rag: %w", err)
+	}
+	return r.AddDocument(ctx, filepath.Base(path), string(data))
+}
+
+func (r *RAG) index(dim int) *VectorIndex {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Index == nil {
+		r.Index = NewVectorIndex(dim, Cosine)
+	}
+	return r.Index
+}
+
+// Retrieve returns the TopK chunks closest to question.
+func (r *RAG) Retrieve(ctx context.Context, question string) ([]SearchResult, error) {
+	r.mu.Lock()
+	index := r.Index
+	r.mu.Unlock()
+	if index == nil || index.Len() == 0 {
+		return nil, errors.New("rag: no documents added")
+	}
+	query, err := EmbedTexts(ctx, r.Client, r.embedModel(), []string{question},
+		&genai.EmbedContentConfig{TaskType: "RETRIEVAL_QUERY"})
+	if err != nil {
+		return nil, fmt.Errorf("rag: %w", err)
+	}
+	results, err := index.Search(query[0], r.topK())
+	if err != nil {
+		return nil, fmt.Errorf("rag: %w", err)
+	}
+	return results, nil
+}
+
+const ragInstruction = `Answer the question using only the context passages.
+Each passage starts with its ID in square brackets. After every statement,
+cite the passages that support it by ID, for example [a11.txt#0c61f3a9b2d4].
+If the context does not contain the answer, say that you don't know.`
+
+// Ask retrieves the chunks closest to question and asks Model to answer from
+// them. config, which may be nil, is applied to the generate request; its
+// system instruction is replaced.
+func (r *RAG) Ask(ctx context.Context, question string, config *genai.GenerateContentConfig) (*RAGAnswer, error) {
+	retrieved, err := r.Retrieve(ctx, question)
+	if err != nil {
+		return nil, err
+	}
+	var prompt strings.Builder
+	prompt.WriteString("Context:\n\n")
+	for _, res := range retrieved {
+		fmt.Fprintf(&prompt, "[%s]\n%s\n\n", res.ID, res.Metadata["text"])
+	}
+	prompt.WriteString("Question: " + question)
+
+	var cfg genai.GenerateContentConfig
+	if config != nil {
+		cfg = *config
+	}
+	cfg.SystemInstruction = genai.NewContentFromText(ragInstruction, "user")
+	resp, err := r.Client.Models.GenerateContent(ctx, r.Model, genai.Text(prompt.String()), &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("rag: %w", err)
+	}
+	answer := &RAGAnswer{Text: resp.Text(), Retrieved: retrieved, Response: resp}
+	answer.Sources = citedChunks(answer.Text, retrieved)
+	return answer, nil
+}
+
+var citationRE = regexp.MustCompile(`\[([^\[\]]+)\]`)
+
+// citedChunks returns the IDs of retrieved chunks cited in text, in order of
+// first citation. Citations of chunks that were not retrieved are ignored.
+func citedChunks(text string, retrieved []SearchResult) []string {
+	var ids []string
+	for _, m := range citationRE.FindAllStringSubmatch(text, -1) {
+		for _, id := range strings.Split(m[1], ",") {
+			id = strings.TrimSpace(id)
+			known := slices.ContainsFunc(retrieved, func(r SearchResult) bool { return r.ID == id })
+			if known && !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+func RAGLocalDocuments() (*RAGAnswer, error) {
+	// [START rag_local_documents]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rag := NewRAG(client, "gemini-2.0-flash")
+	ids, err := rag.AddFile(ctx, filepath.Join(getMedia(), "a11.txt"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.Info("Indexed", "chunks", len(ids))
+
+	answer, err := rag.Ask(ctx, "What did the crew report right after the Eagle landed?", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.Info("Answer", "text", answer.Text, "sources", answer.Sources)
+	// [END rag_local_documents]
+	return answer, err
+}
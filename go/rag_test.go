@@ -0,0 +1,137 @@
+package examples
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+// ragServer fakes embeddings as hashed bags of words, so texts sharing words
+// are close, and answers by citing the first two passages in the prompt.
+type ragServer struct {
+	mu      sync.Mutex
+	embeds  int
+	prompts []string
+}
+
+func bagOfWords(text string) []float32 {
+	v := make([]float32, 64)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !('a' <= r && r <= 'z')
+	}) {
+		h := fnv.New32a()
+		h.Write([]byte(w))
+		v[h.Sum32()%64]++
+	}
+	return v
+}
+
+func (s *ragServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case strings.HasSuffix(r.URL.Path, ":batchEmbedContents"):
+		var req struct {
+			Requests []struct {
+				Content *genai.Content `json:"content"`
+			} `json:"requests"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		s.embeds++
+		var embeddings []any
+		for _, q := range req.Requests {
+			embeddings = append(embeddings, map[string]any{"values": bagOfWords(q.Content.Parts[0].Text)})
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"embeddings": embeddings})
+	case strings.HasSuffix(r.URL.Path, ":generateContent"):
+		var req struct {
+			Contents []*genai.Content `json:"contents"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt := req.Contents[0].Parts[0].Text
+		s.prompts = append(s.prompts, prompt)
+		ids := regexp.MustCompile(`(?m)^\[(.+)\]$`).FindAllStringSubmatch(prompt, 2)
+		writeJSON(w, http.StatusOK, textResponse(
+			"The eagle has landed [made-up#1]. Tranquility Base ["+ids[1][1]+", "+ids[0][1]+"]. Again ["+ids[0][1]+"]."))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestRAGAnswersFromRetrievedChunks(t *testing.T) {
+	srv := &ragServer{}
+	ctx := context.Background()
+	rag := NewRAG(newTestClient(t, srv), "gemini-2.0-flash")
+	rag.Chunker = &TextChunker{Strategy: ParagraphChunks, MaxTokens: 15}
+	rag.TopK = 2
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	os.WriteFile(path, []byte("Houston, Tranquility Base here. The Eagle has landed.\n\n"+
+		"Roll program complete, pitch is programmed.\n\n"+
+		"Trans-lunar injection burn is GO.\n\nThe crew ate lunch and slept."), 0o644)
+	ids, err := rag.AddFile(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 4 || !strings.HasPrefix(ids[0], "notes.txt#") {
+		t.Fatalf("chunk ids = %v", ids)
+	}
+
+	answer, err := rag.Ask(ctx, "Where has the Eagle landed?", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(answer.Retrieved) != 2 || answer.Retrieved[0].ID != ids[0] {
+		t.Fatalf("retrieved %v, want %s first", answer.Retrieved, ids[0])
+	}
+	want := []string{answer.Retrieved[1].ID, ids[0]}
+	if !slices.Equal(answer.Sources, want) {
+		t.Errorf("sources = %v, want %v", answer.Sources, want)
+	}
+	if p := srv.prompts[0]; !strings.Contains(p, "["+ids[0]+"]\nHouston, Tranquility Base") || !strings.HasSuffix(p, "Question: Where has the Eagle landed?") {
+		t.Errorf("prompt = %q", p)
+	}
+}
+
+func TestRAGAddDocumentConflict(t *testing.T) {
+	ctx := context.Background()
+	rag := NewRAG(newTestClient(t, &ragServer{}), "gemini-2.0-flash")
+	rag.Chunker = &TextChunker{Strategy: ParagraphChunks, MaxTokens: 15}
+
+	text := "Roll program complete, pitch is programmed.\n\nTrans-lunar injection burn is GO."
+	if _, err := rag.AddDocument(ctx, "notes.txt", text); err != nil {
+		t.Fatal(err)
+	}
+	// The new first paragraph is free, the rest collide with the first call.
+	edited := "Houston, Tranquility Base here.\n\n" + text
+	if _, err := rag.AddDocument(ctx, "notes.txt", edited); err == nil {
+		t.Fatal("adding conflicting chunks succeeded")
+	}
+	if n := rag.Index.Len(); n != 2 {
+		t.Errorf("index holds %d chunks after the failed add, want 2", n)
+	}
+}
+
+func TestRAGWithoutDocuments(t *testing.T) {
+	rag := NewRAG(newTestClient(t, &ragServer{}), "gemini-2.0-flash")
+	if _, err := rag.Ask(context.Background(), "Anyone there?", nil); err == nil {
+		t.Error("Ask succeeded on an empty index")
+	}
+}
+
+func TestRAGLocalDocuments(t *testing.T) {
+	_, err := RAGLocalDocuments()
+	if err != nil {
+		t.Errorf("RAGLocalDocuments returned an error: %v", err)
+	}
+}
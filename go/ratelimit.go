@@ -0,0 +1,276 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// RateLimit is a per-model request and token budget. A zero field means
+// that dimension is not limited.
+type RateLimit struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+}
+
+// TokenEstimator guesses how many input tokens a request will consume.
+type TokenEstimator func(ctx context.Context, model string, contents []*genai.Content) (int, error)
+
+// RateLimiter enforces RateLimit budgets with one pair of token buckets per
+// model. It is safe for concurrent use.
+type RateLimiter struct {
+	// Estimate is used by GenerateContent to size requests. If nil,
+	// EstimateTokensHeuristic is used.
+	Estimate TokenEstimator
+
+	mu       sync.Mutex
+	limits   map[string]RateLimit
+	fallback RateLimit
+	buckets  map[string]*modelBuckets
+	now      func() time.Time
+}
+
+type modelBuckets struct {
+	requests *tokenBucket
+	tokens   *tokenBucket
+}
+
+// NewRateLimiter returns a limiter using limits for the listed models and
+// def for any other model.
+func NewRateLimiter(limits map[string]RateLimit, def RateLimit) *RateLimiter {
+	return &RateLimiter{
+		limits:   limits,
+		fallback: def,
+		buckets:  make(map[string]*modelBuckets),
+	}
+}
+
+func (l *RateLimiter) clock() time.Time {
+	if l.now != nil {
+		return l.now()
+	}
+	return time.Now()
+}
+
+// Reservation is the token budget taken for one request. Call Reconcile once
+// the real usage is known.
+type Reservation struct {
+	limiter  *RateLimiter
+	model    string
+	Estimate int
+}
+
+// Reconcile corrects the token bucket of the reservation's model with the
+// number of tokens the request actually used.
+func (r *Reservation) Reconcile(actual int) {
+	if r == nil {
+		return
+	}
+	l := r.limiter
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucketsFor(r.model)
+	if b.tokens != nil {
+		b.tokens.refill(l.clock())
+		b.tokens.take(float64(actual - r.Estimate))
+	}
+	r.Estimate = actual
+}
+
+// Wait blocks until model has budget for one request of the given number of
+// tokens, or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context, model string, tokens int) (*Reservation, error) {
+	for {
+		l.mu.Lock()
+		b := l.bucketsFor(model)
+		now := l.clock()
+		wait := b.delay(now, tokens)
+		if wait == 0 {
+			if b.requests != nil {
+				b.requests.take(1)
+			}
+			if b.tokens != nil {
+				b.tokens.take(float64(tokens))
+			}
+			l.mu.Unlock()
+			return &Reservation{limiter: l, model: model, Estimate: tokens}, nil
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// GenerateContent waits for budget, calls GenerateContent and reconciles the
+// estimate with the response's UsageMetadata.
+func (l *RateLimiter) GenerateContent(
+	ctx context.Context,
+	client *genai.Client,
+	model string,
+	contents []*genai.Content,
+	config *genai.GenerateContentConfig,
+) (*genai.GenerateContentResponse, error) {
+	estimate := l.Estimate
+	if estimate == nil {
+		estimate = EstimateTokensHeuristic
+	}
+	n, err := estimate(ctx, model, contents)
+	if err != nil {
+		return nil, fmt.Errorf("ratelimit: estimating tokens: %w", err)
+	}
+	res, err := l.Wait(ctx, model, n)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Models.GenerateContent(ctx, model, contents, config)
+	if err == nil && resp.UsageMetadata != nil {
+		res.Reconcile(int(resp.UsageMetadata.TotalTokenCount))
+	}
+	return resp, err
+}
+
+func (l *RateLimiter) bucketsFor(model string) *modelBuckets {
+	if b, ok := l.buckets[model]; ok {
+		return b
+	}
+	limit, ok := l.limits[model]
+	if !ok {
+		limit = l.fallback
+	}
+	now := l.clock()
+	b := &modelBuckets{
+		requests: newTokenBucket(limit.RequestsPerMinute, now),
+		tokens:   newTokenBucket(limit.TokensPerMinute, now),
+	}
+	if l.buckets == nil {
+		l.buckets = make(map[string]*modelBuckets)
+	}
+	l.buckets[model] = b
+	return b
+}
+
+// delay returns how long to wait before a request of n tokens fits in both
+// buckets.
+func (b *modelBuckets) delay(now time.Time, n int) time.Duration {
+	var d time.Duration
+	if b.requests != nil {
+		b.requests.refill(now)
+		d = max(d, b.requests.delay(1))
+	}
+	if b.tokens != nil {
+		b.tokens.refill(now)
+		d = max(d, b.tokens.delay(float64(n)))
+	}
+	return d
+}
+
+// tokenBucket refills continuously at perMinute/60 tokens per second up to
+// its capacity. Its level may go negative after a reconciliation shows a
+// request used more than was reserved.
+type tokenBucket struct {
+	capacity float64
+	level    float64
+	perSec   float64
+	last     time.Time
+}
+
+func newTokenBucket(perMinute int, now time.Time) *tokenBucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		capacity: float64(perMinute),
+		level:    float64(perMinute),
+		perSec:   float64(perMinute) / 60,
+		last:     now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.level = math.Min(b.capacity, b.level+elapsed*b.perSec)
+		b.last = now
+	}
+}
+
+func (b *tokenBucket) take(n float64) {
+	b.level -= n
+}
+
+func (b *tokenBucket) delay(n float64) time.Duration {
+	// A request bigger than the whole bucket is let through once it is full.
+	n = math.Min(n, b.capacity)
+	if b.level >= n {
+		return 0
+	}
+	return time.Duration((n - b.level) / b.perSec * float64(time.Second))
+}
+
+// EstimateTokensHeuristic approximates input tokens without a network call
+// using DefaultTokenHeuristics.
+func EstimateTokensHeuristic(_ context.Context, _ string, contents []*genai.Content) (int, error) {
+	return DefaultTokenHeuristics().Contents(contents), nil
+}
+
+// CountTokensEstimator returns a TokenEstimator that asks the API for an
+// exact count.
+func CountTokensEstimator(client *genai.Client) TokenEstimator {
+	return func(ctx context.Context, model string, contents []*genai.Content) (int, error) {
+		resp, err := client.Models.CountTokens(ctx, model, contents, nil)
+		if err != nil {
+			return 0, err
+		}
+		return int(resp.TotalTokens), nil
+	}
+}
+
+func RateLimitedGenerateContent() error {
+	// [START rate_limited_generate_content]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Share one limiter between all goroutines calling the API.
+	limiter := NewRateLimiter(map[string]RateLimit{
+		"gemini-2.0-flash": {RequestsPerMinute: 15, TokensPerMinute: 1000000},
+	}, RateLimit{RequestsPerMinute: 5})
+
+	prompts := []string{
+		"Write a haiku about the sea.",
+		"Write a haiku about the mountains.",
+		"Write a haiku about the desert.",
+	}
+	var wg sync.WaitGroup
+	for _, prompt := range prompts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := limiter.GenerateContent(ctx, client, "gemini-2.0-flash", genai.Text(prompt), nil)
+			if err != nil {
+				log.Fatal(err)
+			}
+			printResponse(response)
+		}()
+	}
+	wg.Wait()
+	// [END rate_limited_generate_content]
+	return nil
+}
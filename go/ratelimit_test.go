@@ -0,0 +1,159 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// fakeClock is a manually advanced clock for limiter tests.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func newTestLimiter(limits map[string]RateLimit) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)}
+	l := NewRateLimiter(limits, RateLimit{})
+	l.now = clock.Now
+	return l, clock
+}
+
+func waitBriefly(l *RateLimiter, model string, tokens int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := l.Wait(ctx, model, tokens)
+	return err
+}
+
+func TestRateLimiterRequestsPerMinute(t *testing.T) {
+	l, clock := newTestLimiter(map[string]RateLimit{"m": {RequestsPerMinute: 2}})
+
+	for i := 0; i < 2; i++ {
+		if err := waitBriefly(l, "m", 0); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if err := waitBriefly(l, "m", 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("third request: got %v, want DeadlineExceeded", err)
+	}
+	// Other models have their own budget.
+	if err := waitBriefly(l, "other", 0); err != nil {
+		t.Fatalf("unlimited model: %v", err)
+	}
+
+	clock.Advance(30 * time.Second)
+	if err := waitBriefly(l, "m", 0); err != nil {
+		t.Fatalf("after refill: %v", err)
+	}
+}
+
+func TestRateLimiterZeroValue(t *testing.T) {
+	// With no limits every model is unlimited.
+	var l RateLimiter
+	for i := range 3 {
+		if err := waitBriefly(&l, "m", 1000); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+}
+
+func TestRateLimiterTokensPerMinuteReconcile(t *testing.T) {
+	l, clock := newTestLimiter(map[string]RateLimit{"m": {TokensPerMinute: 600}})
+
+	res, err := l.Wait(context.Background(), "m", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The request really used the whole minute's budget and then some.
+	res.Reconcile(700)
+	if err := waitBriefly(l, "m", 10); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want DeadlineExceeded after over-use", err)
+	}
+
+	// 600 TPM refills 10 tokens a second; 11s covers the 100 token debt plus 10.
+	clock.Advance(11 * time.Second)
+	if err := waitBriefly(l, "m", 10); err != nil {
+		t.Fatalf("after refill: %v", err)
+	}
+}
+
+func TestRateLimiterConcurrent(t *testing.T) {
+	l, _ := newTestLimiter(map[string]RateLimit{"m": {RequestsPerMinute: 50}})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	granted := 0
+	for i := 0; i < 80; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if waitBriefly(l, "m", 0) == nil {
+				mu.Lock()
+				granted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if granted != 50 {
+		t.Errorf("granted %d requests, want 50", granted)
+	}
+}
+
+func TestRateLimiterGenerateContent(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := textResponse("ok")
+		resp["usageMetadata"] = map[string]any{"promptTokenCount": 40, "totalTokenCount": 50}
+		writeJSON(w, http.StatusOK, resp)
+	}))
+	l, _ := newTestLimiter(map[string]RateLimit{"m": {TokensPerMinute: 1000}})
+	l.Estimate = func(context.Context, string, []*genai.Content) (int, error) { return 10, nil }
+
+	if _, err := l.GenerateContent(context.Background(), client, "m", genai.Text("hi"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.buckets["m"].tokens.level; got != 950 {
+		t.Errorf("token bucket level = %v, want 950", got)
+	}
+}
+
+func TestEstimateTokensHeuristic(t *testing.T) {
+	contents := []*genai.Content{
+		genai.NewContentFromParts([]*genai.Part{
+			genai.NewPartFromText("12345678"),
+			genai.NewPartFromURI("https://example.com/f", "image/jpeg"),
+		}, "user"),
+	}
+	got, err := EstimateTokensHeuristic(context.Background(), "m", contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 2 + 258; got != want {
+		t.Errorf("EstimateTokensHeuristic = %d, want %d", got, want)
+	}
+}
+
+func TestRateLimitedGenerateContent(t *testing.T) {
+	err := RateLimitedGenerateContent()
+	if err != nil {
+		t.Errorf("RateLimitedGenerateContent returned an error: %v", err)
+	}
+}
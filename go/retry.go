@@ -0,0 +1,336 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// Operation identifies the kind of API call carried by an HTTP request.
+type Operation string
+
+const (
+	OpGenerateContent       Operation = "generateContent"
+	OpGenerateContentStream Operation = "streamGenerateContent"
+	OpCountTokens           Operation = "countTokens"
+	OpEmbedContent          Operation = "embedContent"
+	OpModelsGet             Operation = "models.get"
+	OpModelsList            Operation = "models.list"
+	OpFilesUploadStart      Operation = "files.upload.start"
+	OpFilesUploadChunk      Operation = "files.upload.chunk"
+	OpFilesGet              Operation = "files.get"
+	OpFilesList             Operation = "files.list"
+	OpFilesDelete           Operation = "files.delete"
+	OpCachesCreate          Operation = "caches.create"
+	OpCachesGet             Operation = "caches.get"
+	OpCachesList            Operation = "caches.list"
+	OpCachesUpdate          Operation = "caches.update"
+	OpCachesDelete          Operation = "caches.delete"
+	OpUnknown               Operation = "unknown"
+)
+
+// defaultIdempotent lists the operations that are safe to send again when
+// the outcome of the first attempt is unknown. Creating a cache or sending an
+// upload chunk twice can leave duplicate state behind, so those are only
+// retried when the server explicitly rejected the request.
+var defaultIdempotent = map[Operation]bool{
+	OpGenerateContent:       true,
+	OpGenerateContentStream: true,
+	OpCountTokens:           true,
+	OpEmbedContent:          true,
+	OpModelsGet:             true,
+	OpModelsList:            true,
+	OpFilesUploadStart:      true,
+	OpFilesGet:              true,
+	OpFilesList:             true,
+	OpFilesDelete:           true,
+	OpCachesGet:             true,
+	OpCachesList:            true,
+	OpCachesUpdate:          true,
+	OpCachesDelete:          true,
+}
+
+// RetryPolicy configures how RetryTransport retries failed calls.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// InitialDelay is the wait before the first retry.
+	InitialDelay time.Duration
+	// MaxDelay caps the wait between two attempts.
+	MaxDelay time.Duration
+	// Multiplier grows the delay after every attempt.
+	Multiplier float64
+	// Jitter is the fraction of each delay that is randomized, between 0 and 1.
+	Jitter float64
+	// MaxElapsed bounds the total time spent retrying. Zero means no limit.
+	MaxElapsed time.Duration
+	// Idempotent overrides the default idempotency of individual operations.
+	Idempotent map[Operation]bool
+}
+
+// DefaultRetryPolicy returns a policy suitable for the examples: up to five
+// attempts over at most two minutes.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  5,
+		InitialDelay: 1 * time.Second,
+		MaxDelay:     30 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.2,
+		MaxElapsed:   2 * time.Minute,
+	}
+}
+
+func (p RetryPolicy) idempotent(op Operation) bool {
+	if v, ok := p.Idempotent[op]; ok {
+		return v
+	}
+	return defaultIdempotent[op]
+}
+
+// backoff returns the delay before retry number n (starting at 1).
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(n-1))
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d -= d * p.Jitter * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// RetryTransport is an http.RoundTripper that retries transient failures.
+// Pass it to genai.ClientConfig.HTTPClient so every SDK call goes through it.
+type RetryTransport struct {
+	Policy RetryPolicy
+	// Base is the transport used to send requests. If nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+}
+
+// NewRetryingHTTPClient returns an HTTP client that retries according to p.
+func NewRetryingHTTPClient(p RetryPolicy) *http.Client {
+	return &http.Client{Transport: &RetryTransport{Policy: p}}
+}
+
+func (t *RetryTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	getBody, err := rewindableBody(req)
+	if err != nil {
+		return nil, err
+	}
+	op := classifyRequest(req)
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		// A RoundTripper must not modify the caller's request, so every
+		// attempt that needs a fresh body is sent on a clone.
+		out := req
+		if getBody != nil && (attempt > 1 || req.GetBody == nil) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			out = req.Clone(req.Context())
+			out.Body = body
+		}
+		resp, err := t.base().RoundTrip(out)
+
+		retry, wait := t.shouldRetry(op, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+		if t.Policy.MaxElapsed > 0 && time.Since(start)+wait > t.Policy.MaxElapsed {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry decides whether another attempt should be made and how long to
+// wait before it.
+func (t *RetryTransport) shouldRetry(op Operation, resp *http.Response, err error, attempt int) (bool, time.Duration) {
+	if attempt >= t.Policy.MaxAttempts {
+		return false, 0
+	}
+	wait := t.Policy.backoff(attempt)
+	if err != nil {
+		// The request may or may not have reached the server.
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false, 0
+		}
+		return t.Policy.idempotent(op), wait
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		// The server refused the request, so it is safe to send it again.
+		if ra, ok := retryAfter(resp.Header, time.Now()); ok && ra > wait {
+			wait = ra
+		}
+		return true, wait
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return t.Policy.idempotent(op), wait
+	}
+	return false, 0
+}
+
+// rewindableBody returns a function that produces a fresh copy of the request
+// body for each attempt. Bodies without GetBody are read into memory; the
+// original is consumed and closed.
+func rewindableBody(req *http.Request) (func() (io.ReadCloser, error), error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		return req.GetBody, nil
+	}
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("retry: reading request body: %w", err)
+	}
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}, nil
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// classifyRequest maps a Gemini API request to the operation it performs.
+func classifyRequest(req *http.Request) Operation {
+	// The SDK joins the base URL and path with a slash, so collapse any doubles.
+	path := "/" + strings.TrimLeft(req.URL.Path, "/")
+	if req.Header.Get("X-Goog-Upload-Command") != "" && !strings.Contains(req.Header.Get("X-Goog-Upload-Command"), "start") {
+		return OpFilesUploadChunk
+	}
+	switch {
+	case strings.HasPrefix(path, "/upload/"):
+		return OpFilesUploadStart
+	case strings.HasSuffix(path, ":generateContent"):
+		return OpGenerateContent
+	case strings.HasSuffix(path, ":streamGenerateContent"):
+		return OpGenerateContentStream
+	case strings.HasSuffix(path, ":countTokens"):
+		return OpCountTokens
+	case strings.HasSuffix(path, ":embedContent"), strings.HasSuffix(path, ":batchEmbedContents"):
+		return OpEmbedContent
+	}
+
+	resource, name := splitResource(path)
+	switch resource {
+	case "models":
+		if name == "" {
+			return OpModelsList
+		}
+		return OpModelsGet
+	case "files":
+		switch {
+		case req.Method == http.MethodDelete:
+			return OpFilesDelete
+		case name == "":
+			return OpFilesList
+		}
+		return OpFilesGet
+	case "cachedContents":
+		switch {
+		case req.Method == http.MethodPost:
+			return OpCachesCreate
+		case req.Method == http.MethodPatch:
+			return OpCachesUpdate
+		case req.Method == http.MethodDelete:
+			return OpCachesDelete
+		case name == "":
+			return OpCachesList
+		}
+		return OpCachesGet
+	}
+	return OpUnknown
+}
+
+// splitResource returns the collection and resource name of a path such as
+// /v1beta/files/abc-123.
+func splitResource(path string) (collection, name string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 {
+		return "", ""
+	}
+	// parts[0] is the API version.
+	collection = parts[1]
+	if len(parts) > 2 {
+		name = parts[2]
+	}
+	return collection, name
+}
+
+func RetryGenerateContent() (*genai.GenerateContentResponse, error) {
+	// [START retry_generate_content]
+	ctx := context.Background()
+	policy := DefaultRetryPolicy()
+	policy.MaxElapsed = time.Minute
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(policy),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Transient 429 and 503 responses are retried before an error reaches here.
+	response, err := client.Models.GenerateContent(
+		ctx,
+		"gemini-2.0-flash",
+		genai.Text("Write a story about a magic backpack."),
+		nil,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printResponse(response)
+	// [END retry_generate_content]
+	return response, err
+}
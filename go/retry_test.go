@@ -0,0 +1,305 @@
+package examples
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// fastRetryPolicy keeps the tests quick while still exercising backoff.
+func fastRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  4,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     5 * time.Millisecond,
+		Multiplier:   2,
+	}
+}
+
+// failFirst returns a handler that answers the first n requests with status
+// and then hands over to next.
+func failFirst(n int32, status int, next http.HandlerFunc) (http.HandlerFunc, *atomic.Int32) {
+	var calls atomic.Int32
+	return func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= n {
+			writeAPIError(w, status, http.StatusText(status), "injected failure")
+			return
+		}
+		next(w, r)
+	}, &calls
+}
+
+func TestRetryTransportGenerateContent(t *testing.T) {
+	h, calls := failFirst(2, http.StatusServiceUnavailable, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, textResponse("ok"))
+	})
+	client := newTestClientWithConfig(t, h, &genai.ClientConfig{
+		HTTPClient: NewRetryingHTTPClient(fastRetryPolicy()),
+	})
+
+	resp, err := client.Models.GenerateContent(context.Background(), "gemini-2.0-flash", genai.Text("hi"), nil)
+	if err != nil {
+		t.Fatalf("GenerateContent returned an error: %v", err)
+	}
+	if resp.Text() != "ok" {
+		t.Errorf("Text() = %q, want %q", resp.Text(), "ok")
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("server called %d times, want 3", got)
+	}
+}
+
+func TestRetryTransportGivesUp(t *testing.T) {
+	h, calls := failFirst(100, http.StatusTooManyRequests, nil)
+	client := newTestClientWithConfig(t, h, &genai.ClientConfig{
+		HTTPClient: NewRetryingHTTPClient(fastRetryPolicy()),
+	})
+
+	_, err := client.Models.GenerateContent(context.Background(), "gemini-2.0-flash", genai.Text("hi"), nil)
+	if err == nil {
+		t.Fatal("expected an error after exhausting retries")
+	}
+	if got := calls.Load(); got != 4 {
+		t.Errorf("server called %d times, want 4", got)
+	}
+}
+
+func TestRetryTransportCacheCreateIdempotency(t *testing.T) {
+	// A 500 on Caches.Create is ambiguous and must not be retried.
+	h, calls := failFirst(1, http.StatusInternalServerError, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"name": "cachedContents/abc"})
+	})
+	client := newTestClientWithConfig(t, h, &genai.ClientConfig{
+		HTTPClient: NewRetryingHTTPClient(fastRetryPolicy()),
+	})
+	_, err := client.Caches.Create(context.Background(), "gemini-1.5-flash-001", &genai.CreateCachedContentConfig{
+		Contents: genai.Text("hi"),
+	})
+	if err == nil {
+		t.Fatal("expected Caches.Create to fail on an ambiguous 500")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server called %d times, want 1", got)
+	}
+
+	// A 429 means the request was rejected, so it is retried.
+	h, calls = failFirst(1, http.StatusTooManyRequests, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"name": "cachedContents/abc"})
+	})
+	client = newTestClientWithConfig(t, h, &genai.ClientConfig{
+		HTTPClient: NewRetryingHTTPClient(fastRetryPolicy()),
+	})
+	cache, err := client.Caches.Create(context.Background(), "gemini-1.5-flash-001", &genai.CreateCachedContentConfig{
+		Contents: genai.Text("hi"),
+	})
+	if err != nil {
+		t.Fatalf("Caches.Create returned an error: %v", err)
+	}
+	if cache.Name != "cachedContents/abc" {
+		t.Errorf("cache.Name = %q", cache.Name)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server called %d times, want 2", got)
+	}
+}
+
+func TestRetryTransportUpload(t *testing.T) {
+	var srvURL string
+	var starts atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/upload/v1beta/files", func(w http.ResponseWriter, r *http.Request) {
+		if starts.Add(1) == 1 {
+			writeAPIError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "try again")
+			return
+		}
+		w.Header().Set("X-Goog-Upload-URL", srvURL+"/upload-session/1")
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/upload-session/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Goog-Upload-Status", "final")
+		writeJSON(w, http.StatusOK, map[string]any{
+			"file": map[string]any{"name": "files/abc", "state": "ACTIVE"},
+		})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	srvURL = srv.URL
+
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "test-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPClient:  NewRetryingHTTPClient(fastRetryPolicy()),
+		HTTPOptions: genai.HTTPOptions{BaseURL: srv.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := client.Files.Upload(context.Background(), strings.NewReader("hello"), &genai.UploadFileConfig{
+		MIMEType: "text/plain",
+	})
+	if err != nil {
+		t.Fatalf("Files.Upload returned an error: %v", err)
+	}
+	if file.Name != "files/abc" {
+		t.Errorf("file.Name = %q", file.Name)
+	}
+	if got := starts.Load(); got != 2 {
+		t.Errorf("upload start called %d times, want 2", got)
+	}
+}
+
+func TestRetryTransportMaxElapsed(t *testing.T) {
+	var calls atomic.Int32
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		writeAPIError(w, http.StatusTooManyRequests, "RESOURCE_EXHAUSTED", "slow down")
+	})
+	p := fastRetryPolicy()
+	p.MaxElapsed = time.Second
+	client := newTestClientWithConfig(t, h, &genai.ClientConfig{HTTPClient: NewRetryingHTTPClient(p)})
+
+	start := time.Now()
+	_, err := client.Models.GenerateContent(context.Background(), "gemini-2.0-flash", genai.Text("hi"), nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("gave up after %v, want immediately", elapsed)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server called %d times, want 1", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"7", 7 * time.Second, true},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.value != "" {
+			h.Set("Retry-After", tt.value)
+		}
+		got, ok := retryAfter(h, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestClassifyRequest(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   Operation
+	}{
+		{http.MethodPost, "/v1beta/models/gemini-2.0-flash:generateContent", OpGenerateContent},
+		{http.MethodPost, "/v1beta/models/gemini-2.0-flash:streamGenerateContent", OpGenerateContentStream},
+		{http.MethodPost, "/v1beta/models/gemini-2.0-flash:countTokens", OpCountTokens},
+		{http.MethodGet, "/v1beta/models/gemini-2.0-flash", OpModelsGet},
+		{http.MethodGet, "/v1beta/files", OpFilesList},
+		{http.MethodDelete, "/v1beta/files/abc", OpFilesDelete},
+		{http.MethodPost, "/v1beta/cachedContents", OpCachesCreate},
+		{http.MethodPatch, "/v1beta/cachedContents/abc", OpCachesUpdate},
+		{http.MethodGet, "/v1beta/cachedContents", OpCachesList},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if got := classifyRequest(req); got != tt.want {
+			t.Errorf("classifyRequest(%s %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRetryTransportLeavesRequestAlone(t *testing.T) {
+	var bodies []string
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		status := http.StatusOK
+		if len(bodies) == 1 {
+			status = http.StatusServiceUnavailable
+		}
+		return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	})
+	transport := &RetryTransport{Policy: fastRetryPolicy(), Base: base}
+
+	// io.MultiReader hides the type, so the request has no GetBody.
+	body := io.NopCloser(io.MultiReader(strings.NewReader("payload")))
+	req := httptest.NewRequest(http.MethodPost, "/v1beta/models/m:generateContent", nil)
+	req.Body = body
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Body != body {
+		t.Error("RoundTrip replaced the caller's request body")
+	}
+	if len(bodies) != 2 || bodies[0] != "payload" || bodies[1] != "payload" {
+		t.Errorf("attempts sent bodies %q, want the payload twice", bodies)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// TestClassifyRequestSDKPaths classifies the requests the SDK actually
+// builds, whose paths start with a double slash when BaseURL ends in one.
+func TestClassifyRequestSDKPaths(t *testing.T) {
+	var ops []Operation
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		ops = append(ops, classifyRequest(r))
+		resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"name":"x"}`))}
+		switch {
+		case r.Header.Get("X-Goog-Upload-Command") == "start":
+			resp.Header.Set("X-Goog-Upload-URL", "https://generativelanguage.googleapis.com/upload/v1beta/files?upload_id=1")
+		case r.Header.Get("X-Goog-Upload-Command") != "":
+			resp.Header.Set("X-Goog-Upload-Status", "final")
+			resp.Body = io.NopCloser(strings.NewReader(`{"file":{"name":"files/x"}}`))
+		}
+		return resp, nil
+	})
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:      "test-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPClient:  &http.Client{Transport: base},
+		HTTPOptions: genai.HTTPOptions{BaseURL: "https://generativelanguage.googleapis.com/"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Models.GenerateContent(ctx, "gemini-2.0-flash", genai.Text("hi"), nil)
+	client.Models.Get(ctx, "gemini-2.0-flash", nil)
+	client.Files.Upload(ctx, strings.NewReader("hello"), &genai.UploadFileConfig{MIMEType: "text/plain"})
+	client.Caches.Create(ctx, "gemini-1.5-flash-001", &genai.CreateCachedContentConfig{Contents: genai.Text("hi")})
+
+	want := []Operation{OpGenerateContent, OpModelsGet, OpFilesUploadStart, OpFilesUploadChunk, OpCachesCreate}
+	if !slices.Equal(ops, want) {
+		t.Errorf("operations = %v, want %v", ops, want)
+	}
+}
+
+func TestRetryGenerateContent(t *testing.T) {
+	_, err := RetryGenerateContent()
+	if err != nil {
+		t.Errorf("RetryGenerateContent returned an error: %v", err)
+	}
+}
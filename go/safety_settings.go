@@ -2,10 +2,9 @@ package examples
 
 import (
 	"context"
-	"fmt"
-	"os"
 	"log"
-	"encoding/json"
+	"log/slog"
+	"os"
 
 	"google.golang.org/genai"
 )
@@ -14,8 +13,9 @@ func SafetySettings() error {
 	// [START safety_settings]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -40,16 +40,19 @@ func SafetySettings() error {
 		log.Fatal(err)
 	}
 
-	// Print the finish reason and safety ratings from the first candidate.
+	// Log the finish reason and safety ratings from the first candidate.
 	if len(response.Candidates) > 0 {
-		fmt.Println("Finish reason:", response.Candidates[0].FinishReason)
-		safetyRatings, err := json.MarshalIndent(response.Candidates[0].SafetyRatings, "", "  ")
-		if err != nil {
-			return err
+		candidate := response.Candidates[0]
+		slog.Info("Finish reason", "finish_reason", candidate.FinishReason)
+		for _, rating := range candidate.SafetyRatings {
+			slog.Info("Safety rating",
+				"category", rating.Category,
+				"probability", rating.Probability,
+				"blocked", rating.Blocked,
+			)
 		}
-		fmt.Println("Safety ratings:", string(safetyRatings))
 	} else {
-		fmt.Println("No candidate returned.")
+		slog.Warn("No candidate returned.")
 	}
 	// [END safety_settings]
 	return err
@@ -59,8 +62,9 @@ func SafetySettingsMulti() error {
 	// [START safety_settings_multi]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -89,20 +93,22 @@ func SafetySettingsMulti() error {
 		log.Fatal(err)
 	}
 
-	// Print the generated text.
-	text := response.Text()
-	fmt.Println("Generated text:", text)
+	// Log the generated text.
+	printResponse(response)
 
-	// Print the and safety ratings from the first candidate.
+	// Log the safety ratings from the first candidate.
 	if len(response.Candidates) > 0 {
-		fmt.Println("Finish reason:", response.Candidates[0].FinishReason)
-		safetyRatings, err := json.MarshalIndent(response.Candidates[0].SafetyRatings, "", "  ")
-		if err != nil {
-			return err
+		candidate := response.Candidates[0]
+		slog.Info("Finish reason", "finish_reason", candidate.FinishReason)
+		for _, rating := range candidate.SafetyRatings {
+			slog.Info("Safety rating",
+				"category", rating.Category,
+				"probability", rating.Probability,
+				"blocked", rating.Blocked,
+			)
 		}
-		fmt.Println("Safety ratings:", string(safetyRatings))
 	} else {
-		fmt.Println("No candidate returned.")
+		slog.Warn("No candidate returned.")
 	}
 	// [END safety_settings_multi]
 	return err
@@ -0,0 +1,338 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genai"
+)
+
+const instrumentationName = "gemini-api-examples"
+
+// TelemetryTransport is an http.RoundTripper that records an OpenTelemetry
+// span and metrics for every Gemini API call made through it. Wrap a
+// RetryTransport with it to get one span per logical call, or put it as the
+// RetryTransport's Base to get one span per attempt.
+type TelemetryTransport struct {
+	// Base is the transport used to send requests. If nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+
+	tracer     trace.Tracer
+	duration   metric.Float64Histogram
+	firstChunk metric.Float64Histogram
+	tokenUsage metric.Int64Histogram
+}
+
+// NewTelemetryTransport returns a transport reporting to the given providers.
+// Nil providers fall back to the global ones.
+func NewTelemetryTransport(base http.RoundTripper, tp trace.TracerProvider, mp metric.MeterProvider) (*TelemetryTransport, error) {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	meter := mp.Meter(instrumentationName)
+	t := &TelemetryTransport{Base: base, tracer: tp.Tracer(instrumentationName)}
+
+	var err error
+	t.duration, err = meter.Float64Histogram("gen_ai.client.operation.duration",
+		metric.WithDescription("Duration of Gemini API calls."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	t.firstChunk, err = meter.Float64Histogram("gen_ai.client.time_to_first_chunk",
+		metric.WithDescription("Time until the first chunk of a streamed response arrives."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	t.tokenUsage, err = meter.Int64Histogram("gen_ai.client.token.usage",
+		metric.WithDescription("Tokens reported in UsageMetadata."),
+		metric.WithUnit("{token}"))
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *TelemetryTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *TelemetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	op := classifyRequest(req)
+	attrs := []attribute.KeyValue{
+		attribute.String("gen_ai.system", "gemini"),
+		attribute.String("gen_ai.operation.name", string(op)),
+	}
+	if model := modelFromPath(req.URL.Path); model != "" {
+		attrs = append(attrs, attribute.String("gen_ai.request.model", model))
+	}
+	ctx, span := t.tracer.Start(req.Context(), "genai."+string(op),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+	rec := &callRecord{t: t, ctx: ctx, span: span, attrs: attrs, start: time.Now()}
+
+	resp, err := t.base().RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		rec.finish(err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		rec.finish(statusError{code: resp.StatusCode, status: resp.Status})
+		return resp, nil
+	}
+
+	switch op {
+	case OpGenerateContentStream:
+		resp.Body = &streamBody{
+			rc:      resp.Body,
+			onFirst: rec.firstChunk,
+			onData:  rec.observe,
+			onDone:  rec.finish,
+		}
+	case OpGenerateContent, OpCountTokens, OpEmbedContent:
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			rec.finish(err)
+			return resp, nil
+		}
+		rec.observe(body)
+		rec.finish(nil)
+	default:
+		rec.finish(nil)
+	}
+	return resp, nil
+}
+
+// callRecord collects what is known about one call until it ends.
+type callRecord struct {
+	t     *TelemetryTransport
+	ctx   context.Context
+	span  trace.Span
+	attrs []attribute.KeyValue
+	start time.Time
+	usage *genai.GenerateContentResponseUsageMetadata
+	once  sync.Once
+}
+
+// usageChunk is the subset of a response body the transport looks at.
+type usageChunk struct {
+	ModelVersion  string                                      `json:"modelVersion"`
+	UsageMetadata *genai.GenerateContentResponseUsageMetadata `json:"usageMetadata"`
+	Candidates    []struct {
+		FinishReason genai.FinishReason `json:"finishReason"`
+	} `json:"candidates"`
+	TotalTokens             *int32 `json:"totalTokens"`
+	CachedContentTokenCount int32  `json:"cachedContentTokenCount"`
+}
+
+// observe copies token counts and finish reasons from a response body onto
+// the span. Later chunks of a stream overwrite earlier ones.
+func (r *callRecord) observe(body []byte) {
+	var c usageChunk
+	if json.Unmarshal(body, &c) != nil {
+		return
+	}
+	if c.ModelVersion != "" {
+		r.span.SetAttributes(attribute.String("gen_ai.response.model", c.ModelVersion))
+	}
+	if c.TotalTokens != nil {
+		r.span.SetAttributes(attribute.Int("gen_ai.usage.input_tokens", int(*c.TotalTokens)))
+	}
+	if c.CachedContentTokenCount > 0 {
+		r.span.SetAttributes(attribute.Int("gen_ai.usage.cached_tokens", int(c.CachedContentTokenCount)))
+	}
+	var reasons []string
+	for _, cand := range c.Candidates {
+		if cand.FinishReason != "" {
+			reasons = append(reasons, string(cand.FinishReason))
+		}
+	}
+	if len(reasons) > 0 {
+		r.span.SetAttributes(attribute.StringSlice("gen_ai.response.finish_reasons", reasons))
+	}
+	if u := c.UsageMetadata; u != nil {
+		r.span.SetAttributes(
+			attribute.Int("gen_ai.usage.input_tokens", int(u.PromptTokenCount)),
+			attribute.Int("gen_ai.usage.output_tokens", int(u.CandidatesTokenCount)),
+			attribute.Int("gen_ai.usage.thoughts_tokens", int(u.ThoughtsTokenCount)),
+			attribute.Int("gen_ai.usage.cached_tokens", int(u.CachedContentTokenCount)),
+			attribute.Bool("gen_ai.cache.hit", u.CachedContentTokenCount > 0),
+		)
+		r.usage = u
+	}
+}
+
+// firstChunk records the time to the first streamed chunk.
+func (r *callRecord) firstChunk() {
+	r.t.firstChunk.Record(r.ctx, time.Since(r.start).Seconds(), metric.WithAttributes(r.attrs...))
+}
+
+// finish ends the span and records metrics exactly once.
+func (r *callRecord) finish(err error) {
+	r.once.Do(func() {
+		attrs := r.attrs
+		if err != nil {
+			r.span.RecordError(err)
+			r.span.SetStatus(codes.Error, err.Error())
+			attrs = append(attrs, attribute.String("error.type", errorType(err)))
+		}
+		set := metric.WithAttributes(attrs...)
+		r.t.duration.Record(r.ctx, time.Since(r.start).Seconds(), set)
+		if u := r.usage; u != nil {
+			r.t.tokenUsage.Record(r.ctx, int64(u.PromptTokenCount),
+				metric.WithAttributes(append(attrs, attribute.String("gen_ai.token.type", "input"))...))
+			r.t.tokenUsage.Record(r.ctx, int64(u.CandidatesTokenCount),
+				metric.WithAttributes(append(attrs, attribute.String("gen_ai.token.type", "output"))...))
+		}
+		r.span.End()
+	})
+}
+
+// statusError is an HTTP error response seen by the transport.
+type statusError struct {
+	code   int
+	status string
+}
+
+func (e statusError) Error() string { return e.status }
+
+// errorType returns a low-cardinality label for err, suitable for metrics.
+func errorType(err error) string {
+	if se, ok := err.(statusError); ok {
+		return strconv.Itoa(se.code)
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return "cancelled"
+	}
+	return "transport"
+}
+
+// streamBody watches a server-sent event stream as the SDK reads it.
+// onFirst runs when the first bytes arrive, onData for every "data:" line and
+// onDone once when the stream ends or is closed.
+type streamBody struct {
+	rc      io.ReadCloser
+	onFirst func()
+	onData  func([]byte)
+	onDone  func(error)
+
+	started bool
+	pending []byte
+	done    sync.Once
+}
+
+func (b *streamBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	if n > 0 {
+		if !b.started {
+			b.started = true
+			if b.onFirst != nil {
+				b.onFirst()
+			}
+		}
+		b.pending = append(b.pending, p[:n]...)
+		b.scan()
+	}
+	if err == io.EOF {
+		b.finish(nil)
+	} else if err != nil {
+		b.finish(err)
+	}
+	return n, err
+}
+
+func (b *streamBody) Close() error {
+	b.finish(nil)
+	return b.rc.Close()
+}
+
+func (b *streamBody) finish(err error) {
+	b.done.Do(func() {
+		if b.onDone != nil {
+			b.onDone(err)
+		}
+	})
+}
+
+// scan passes every complete "data:" line to onData.
+func (b *streamBody) scan() {
+	for {
+		i := bytes.IndexByte(b.pending, '\n')
+		if i < 0 {
+			return
+		}
+		line := bytes.TrimSpace(b.pending[:i])
+		b.pending = b.pending[i+1:]
+		if data, ok := bytes.CutPrefix(line, []byte("data:")); ok && b.onData != nil {
+			b.onData(bytes.TrimSpace(data))
+		}
+	}
+}
+
+// modelFromPath extracts the model ID from paths like
+// /v1beta/models/gemini-2.0-flash:generateContent.
+func modelFromPath(path string) string {
+	_, rest, ok := strings.Cut(path, "/models/")
+	if !ok {
+		return ""
+	}
+	model, _, _ := strings.Cut(rest, ":")
+	return model
+}
+
+func TelemetryGenerateContent() (*genai.GenerateContentResponse, error) {
+	// [START telemetry_generate_content]
+	ctx := context.Background()
+	// Uses the global OpenTelemetry providers; configure exporters as usual.
+	transport, err := NewTelemetryTransport(&RetryTransport{Policy: DefaultRetryPolicy()}, nil, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: &http.Client{Transport: transport},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	response, err := client.Models.GenerateContent(
+		ctx,
+		"gemini-2.0-flash",
+		genai.Text("Write a story about a magic backpack."),
+		nil,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printResponse(response)
+	// [END telemetry_generate_content]
+	return response, err
+}
@@ -0,0 +1,196 @@
+package examples
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/genai"
+)
+
+// newTelemetryTestClient returns a client wired to in-memory span and metric
+// readers.
+func newTelemetryTestClient(t *testing.T, h http.Handler) (*genai.Client, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	t.Helper()
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	transport, err := NewTelemetryTransport(nil, tp, mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClientWithConfig(t, h, &genai.ClientConfig{
+		HTTPClient: &http.Client{Transport: transport},
+	})
+	return client, spans, reader
+}
+
+func spanAttr(s sdktrace.ReadOnlySpan, key string) (attribute.Value, bool) {
+	for _, kv := range s.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func histogramCount(t *testing.T, reader *sdkmetric.ManualReader, name string) uint64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	var n uint64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			switch d := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				for _, p := range d.DataPoints {
+					n += p.Count
+				}
+			case metricdata.Histogram[int64]:
+				for _, p := range d.DataPoints {
+					n += p.Count
+				}
+			}
+		}
+	}
+	return n
+}
+
+func TestTelemetryGenerateContentSpan(t *testing.T) {
+	client, spans, reader := newTelemetryTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := textResponse("hi")
+		resp["modelVersion"] = "gemini-2.0-flash-001"
+		resp["usageMetadata"] = map[string]any{
+			"promptTokenCount":        12,
+			"candidatesTokenCount":    3,
+			"cachedContentTokenCount": 8,
+			"totalTokenCount":         15,
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}))
+
+	resp, err := client.Models.GenerateContent(context.Background(), "gemini-2.0-flash", genai.Text("hi"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text() != "hi" {
+		t.Errorf("response body was not passed through: %q", resp.Text())
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("got %d spans, want 1", len(ended))
+	}
+	s := ended[0]
+	if s.Name() != "genai.generateContent" {
+		t.Errorf("span name = %q", s.Name())
+	}
+	want := map[string]any{
+		"gen_ai.request.model":       "gemini-2.0-flash",
+		"gen_ai.response.model":      "gemini-2.0-flash-001",
+		"gen_ai.usage.input_tokens":  int64(12),
+		"gen_ai.usage.cached_tokens": int64(8),
+		"gen_ai.cache.hit":           true,
+	}
+	for k, v := range want {
+		got, ok := spanAttr(s, k)
+		if !ok || got.AsInterface() != v {
+			t.Errorf("attribute %s = %v, want %v", k, got.AsInterface(), v)
+		}
+	}
+	if got, _ := spanAttr(s, "gen_ai.response.finish_reasons"); fmt.Sprint(got.AsStringSlice()) != "[STOP]" {
+		t.Errorf("finish reasons = %v", got.AsStringSlice())
+	}
+	if n := histogramCount(t, reader, "gen_ai.client.operation.duration"); n != 1 {
+		t.Errorf("duration histogram count = %d, want 1", n)
+	}
+	if n := histogramCount(t, reader, "gen_ai.client.token.usage"); n != 2 {
+		t.Errorf("token usage histogram count = %d, want 2", n)
+	}
+}
+
+func TestTelemetryStreamSpan(t *testing.T) {
+	client, spans, reader := newTelemetryTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		chunks := []map[string]any{textResponse("Hello, "), textResponse("world")}
+		chunks[1]["usageMetadata"] = map[string]any{"promptTokenCount": 4, "candidatesTokenCount": 2}
+		for _, c := range chunks {
+			b, _ := json.Marshal(c)
+			fmt.Fprintf(w, "data: %s\n\n", b)
+			w.(http.Flusher).Flush()
+		}
+	}))
+
+	var text strings.Builder
+	for resp, err := range client.Models.GenerateContentStream(context.Background(), "gemini-2.0-flash", genai.Text("hi"), nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		text.WriteString(resp.Text())
+	}
+	if text.String() != "Hello, world" {
+		t.Errorf("streamed text = %q", text.String())
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("got %d spans, want 1", len(ended))
+	}
+	if got, _ := spanAttr(ended[0], "gen_ai.usage.output_tokens"); got.AsInt64() != 2 {
+		t.Errorf("output tokens = %d, want 2", got.AsInt64())
+	}
+	if n := histogramCount(t, reader, "gen_ai.client.time_to_first_chunk"); n != 1 {
+		t.Errorf("time to first chunk count = %d, want 1", n)
+	}
+}
+
+func TestTelemetryErrorAndFilesSpans(t *testing.T) {
+	client, spans, _ := newTelemetryTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/v1beta/files/") {
+			writeJSON(w, http.StatusOK, map[string]any{"name": "files/abc", "state": "ACTIVE"})
+			return
+		}
+		writeAPIError(w, http.StatusNotFound, "NOT_FOUND", "no such model")
+	}))
+
+	if _, err := client.Models.CountTokens(context.Background(), "retired-model", genai.Text("hi"), nil); err == nil {
+		t.Fatal("expected CountTokens to fail")
+	}
+	if _, err := client.Files.Get(context.Background(), "files/abc", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("got %d spans, want 2", len(ended))
+	}
+	if ended[0].Status().Code != codes.Error {
+		t.Errorf("CountTokens span status = %v, want Error", ended[0].Status())
+	}
+	if ended[1].Name() != "genai.files.get" || ended[1].Status().Code == codes.Error {
+		t.Errorf("Files.Get span = %q %v", ended[1].Name(), ended[1].Status())
+	}
+}
+
+func TestTelemetryGenerateContent(t *testing.T) {
+	_, err := TelemetryGenerateContent()
+	if err != nil {
+		t.Errorf("TelemetryGenerateContent returned an error: %v", err)
+	}
+}
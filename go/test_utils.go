@@ -1,8 +1,8 @@
 package examples
 
 import (
+	"context"
 	"log"
-	"fmt"
 	"path/filepath"
 	"runtime"
 
@@ -23,13 +23,7 @@ func getMedia() string {
 	return filepath.Join(dir, "..", "third_party")
 }
 
-// Helping for printing the response.
+// Helper for logging the response as a structured record to slog.Default().
 func printResponse(resp *genai.GenerateContentResponse) {
-	for _, cand := range resp.Candidates {
-		if cand.Content != nil {
-			for _, part := range cand.Content.Parts {
-				fmt.Println(part.Text)
-			}
-		}
-	}
+	LogConfig{}.LogResponse(context.Background(), resp)
 }
@@ -0,0 +1,417 @@
+package examples
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/genai"
+)
+
+// ChunkStrategy selects where a TextChunker may cut text.
+type ChunkStrategy int
+
+const (
+	// FixedTokens fills each chunk with words up to MaxTokens, ignoring
+	// sentence and paragraph boundaries.
+	FixedTokens ChunkStrategy = iota
+	// SentenceChunks packs whole sentences.
+	SentenceChunks
+	// ParagraphChunks packs whole paragraphs, falling back to sentences and
+	// then words for paragraphs longer than MaxTokens.
+	ParagraphChunks
+	// MarkdownChunks never lets a chunk span two headings. Each section is
+	// packed like ParagraphChunks and its chunks record the heading path.
+	MarkdownChunks
+)
+
+// TextChunk is a piece of a longer text.
+type TextChunk struct {
+	// ID is derived from the source and the chunk text, so it stays the same
+	// when unrelated parts of the document change. A repeat of an earlier
+	// chunk's text also hashes the text of the chunk before it; only a
+	// repeat that follows the same text again is numbered.
+	ID    string
+	Index int
+	Text  string
+	// Start and End are the byte offsets of Text in the original.
+	Start, End int
+	// Tokens is the size of Text as measured by the chunker.
+	Tokens int
+	// Headings are the enclosing markdown headings, outermost first. They
+	// are only set by MarkdownChunks.
+	Headings []string
+}
+
+// TextChunker splits text into chunks of at most MaxTokens tokens.
+//
+// Text is first packed using the offline Heuristics. Each chunk is then
+// measured with Count and packed again, smaller, if it turns out too large,
+// so Count is called about once per chunk. A single paragraph, sentence or
+// word that Count puts above MaxTokens is split further, down to runes; only
+// a single rune can end up in a chunk larger than MaxTokens.
+type TextChunker struct {
+	Strategy ChunkStrategy
+	// MaxTokens is the largest chunk. Zero means 512.
+	MaxTokens int
+	// Overlap is how many tokens of the end of each chunk are repeated at the
+	// start of the next, in whole words, sentences or paragraphs depending on
+	// Strategy. It must be less than MaxTokens. Markdown sections never
+	// overlap.
+	Overlap int
+	// Heuristics estimates text offline. Zero fields take their value from
+	// DefaultTokenHeuristics.
+	Heuristics TokenHeuristics
+	// Count measures chunks, for example CountTokensEstimator. If nil,
+	// Heuristics is used.
+	Count TokenEstimator
+	// Model is passed to Count.
+	Model string
+}
+
+type textSpan struct {
+	start, end int
+	// tokens is the offline estimate for the span and the whitespace after it.
+	tokens int
+}
+
+func (c *TextChunker) maxTokens() int {
+	if c.MaxTokens > 0 {
+		return c.MaxTokens
+	}
+	return 512
+}
+
+// Split chunks text. source names the document in chunk IDs.
+func (c *TextChunker) Split(ctx context.Context, source, text string) ([]TextChunk, error) {
+	if c.Overlap < 0 || c.Overlap >= c.maxTokens() {
+		return nil, fmt.Errorf("text chunker: overlap %d must be from 0 to below MaxTokens %d", c.Overlap, c.maxTokens())
+	}
+	type section struct {
+		units    []textSpan
+		headings []string
+	}
+	var sections []section
+	whole := textSpan{0, len(text), 0}
+	switch c.Strategy {
+	case FixedTokens:
+		sections = []section{{units: c.refine(text, splitWords(text, whole), levelWords)}}
+	case SentenceChunks:
+		sections = []section{{units: c.refine(text, splitSentences(text, whole), levelSentences)}}
+	case ParagraphChunks:
+		sections = []section{{units: c.refine(text, splitParagraphSpans(text, whole), levelParagraphs)}}
+	case MarkdownChunks:
+		for _, s := range markdownSections(text) {
+			sections = append(sections, section{
+				units:    c.refine(text, splitParagraphSpans(text, s.span), levelParagraphs),
+				headings: s.headings,
+			})
+		}
+	default:
+		return nil, fmt.Errorf("text chunker: unknown strategy %d", c.Strategy)
+	}
+
+	count := c.Count
+	if count == nil {
+		count = c.Heuristics.Estimator()
+	}
+	var chunks []TextChunk
+	for _, s := range sections {
+		c.estimateUnits(text, s.units)
+		var err error
+		chunks, err = c.pack(ctx, count, text, s.units, c.maxTokens(), s.headings, chunks)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i := range chunks {
+		id := chunkID(source, chunks[i].Text)
+		if seen[id] {
+			// Repeated text: tell the repeats apart by what precedes them,
+			// which unlike a count survives edits earlier in the document.
+			id = chunkID(source, chunks[i].Text+"\x00"+chunks[i-1].Text)
+			for n, base := 2, id; seen[id]; n++ {
+				id = fmt.Sprintf("%s-%d", base, n)
+			}
+		}
+		seen[id] = true
+		chunks[i].ID, chunks[i].Index = id, i
+	}
+	return chunks, nil
+}
+
+func chunkID(source, key string) string {
+	sum := sha256.Sum256([]byte(source + "\x00" + key))
+	return source + "#" + hex.EncodeToString(sum[:6])
+}
+
+// pack greedily fills chunks of at most budget estimated tokens, measures
+// each with count and packs any that are too large again with a smaller
+// budget.
+func (c *TextChunker) pack(ctx context.Context, count TokenEstimator, text string, units []textSpan, budget int, headings []string, out []TextChunk) ([]TextChunk, error) {
+	for first := 0; first < len(units); {
+		last, total := first, units[first].tokens
+		for last+1 < len(units) && total+units[last+1].tokens <= budget {
+			last++
+			total += units[last].tokens
+		}
+		start, end := units[first].start, units[last].end
+		tokens, err := count(ctx, c.Model, genai.Text(text[start:end]))
+		if err != nil {
+			return nil, fmt.Errorf("text chunker: count tokens: %w", err)
+		}
+		parts := units[first : last+1]
+		if tokens > c.maxTokens() && last == first {
+			parts = splitFiner(text, units[first])
+			c.estimateUnits(text, parts)
+		}
+		if tokens > c.maxTokens() && len(parts) > 1 {
+			// The estimate was low; shrink the budget by the same ratio.
+			smaller := max(min(total*c.maxTokens()/tokens, total-1), 1)
+			out, err = c.pack(ctx, count, text, parts, smaller, headings, out)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			out = append(out, TextChunk{Text: text[start:end], Start: start, End: end, Tokens: tokens, Headings: headings})
+		}
+		if last+1 == len(units) {
+			break
+		}
+		// Step back over as many trailing units as fit in Overlap, always
+		// moving forward by at least one unit.
+		next, overlap := last+1, 0
+		for next-1 > first && overlap+units[next-1].tokens <= c.Overlap {
+			next--
+			overlap += units[next].tokens
+		}
+		first = next
+	}
+	return out, nil
+}
+
+// estimateUnits sets each unit's offline token estimate, counting the
+// whitespace up to the next unit so that the estimates of adjacent units
+// add up to at least the estimate of their joined text.
+func (c *TextChunker) estimateUnits(text string, units []textSpan) {
+	for i := range units {
+		end := units[i].end
+		if i+1 < len(units) {
+			end = units[i+1].start
+		}
+		units[i].tokens = max(c.Heuristics.Text(text[units[i].start:end]), 1)
+	}
+}
+
+// splitFiner cuts u into sentences, else words, else two halves of runes.
+// It returns u alone if u is a single rune.
+func splitFiner(text string, u textSpan) []textSpan {
+	if parts := splitSentences(text, u); len(parts) > 1 {
+		return parts
+	}
+	if parts := splitWords(text, u); len(parts) > 1 {
+		return parts
+	}
+	if n := utf8.RuneCountInString(text[u.start:u.end]); n > 1 {
+		return splitRunes(text, u, (n+1)/2)
+	}
+	return []textSpan{u}
+}
+
+const (
+	levelParagraphs = iota
+	levelSentences
+	levelWords
+	levelRunes
+)
+
+// refine splits units that are estimated to be larger than MaxTokens at the
+// next finer level, down to single runes.
+func (c *TextChunker) refine(text string, units []textSpan, level int) []textSpan {
+	h := c.Heuristics.withDefaults()
+	var out []textSpan
+	for _, u := range units {
+		if level == levelRunes || h.Text(text[u.start:u.end]) <= c.maxTokens() {
+			out = append(out, u)
+			continue
+		}
+		var parts []textSpan
+		switch level {
+		case levelParagraphs:
+			parts = splitSentences(text, u)
+		case levelSentences:
+			parts = splitWords(text, u)
+		case levelWords:
+			parts = splitRunes(text, u, max(int(float64(c.maxTokens())*h.CharsPerToken), 1))
+		}
+		out = append(out, c.refine(text, parts, level+1)...)
+	}
+	return out
+}
+
+var (
+	paragraphBreakRE = regexp.MustCompile(`\n[ \t]*\n\s*`)
+	sentenceBreakRE  = regexp.MustCompile(`[.!?]+["')\]]*\s+|\n[ \t]*\n\s*`)
+	wordRE           = regexp.MustCompile(`\S+`)
+)
+
+// splitAt cuts s after the non-space part of each match of re and trims the
+// pieces.
+func splitAt(text string, s textSpan, re *regexp.Regexp) []textSpan {
+	var out []textSpan
+	add := func(start, end int) {
+		piece := text[start:end]
+		trimmed := strings.TrimLeftFunc(piece, unicode.IsSpace)
+		start += len(piece) - len(trimmed)
+		end = start + len(strings.TrimRightFunc(trimmed, unicode.IsSpace))
+		if end > start {
+			out = append(out, textSpan{start: start, end: end})
+		}
+	}
+	from := s.start
+	for _, m := range re.FindAllStringIndex(text[s.start:s.end], -1) {
+		cut := s.start + m[0] + len(strings.TrimRightFunc(text[s.start+m[0]:s.start+m[1]], unicode.IsSpace))
+		add(from, cut)
+		from = s.start + m[1]
+	}
+	add(from, s.end)
+	return out
+}
+
+func splitParagraphSpans(text string, s textSpan) []textSpan {
+	return splitAt(text, s, paragraphBreakRE)
+}
+
+func splitSentences(text string, s textSpan) []textSpan {
+	return splitAt(text, s, sentenceBreakRE)
+}
+
+func splitWords(text string, s textSpan) []textSpan {
+	var out []textSpan
+	for _, m := range wordRE.FindAllStringIndex(text[s.start:s.end], -1) {
+		out = append(out, textSpan{start: s.start + m[0], end: s.start + m[1]})
+	}
+	return out
+}
+
+// splitRunes cuts s into pieces of at most n runes.
+func splitRunes(text string, s textSpan, n int) []textSpan {
+	var out []textSpan
+	start, runes := s.start, 0
+	for i := s.start; i < s.end; {
+		_, size := utf8.DecodeRuneInString(text[i:s.end])
+		i += size
+		if runes++; runes == n || i == s.end {
+			out = append(out, textSpan{start: start, end: i})
+			start, runes = i, 0
+		}
+	}
+	return out
+}
+
+type markdownSection struct {
+	span     textSpan
+	headings []string
+}
+
+var headingRE = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)[ \t#]*$`)
+
+// markdownSections cuts text before every ATX heading outside code fences.
+// Each section starts with its heading line.
+func markdownSections(text string) []markdownSection {
+	var sections []markdownSection
+	var path []string
+	var levels []int
+	start, fence := 0, ""
+	for pos := 0; pos < len(text); {
+		end := strings.IndexByte(text[pos:], '\n') + pos + 1
+		if end == pos {
+			end = len(text)
+		}
+		line := strings.TrimRight(text[pos:end], "\r\n")
+		trimmed := strings.TrimLeft(line, " ")
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		default:
+			if m := headingRE.FindStringSubmatch(line); m != nil {
+				if pos > start {
+					sections = append(sections, markdownSection{textSpan{start, pos, 0}, path})
+				}
+				level := len(m[1])
+				for len(levels) > 0 && levels[len(levels)-1] >= level {
+					levels, path = levels[:len(levels)-1], path[:len(path)-1]
+				}
+				levels = append(levels, level)
+				path = append(path[:len(path):len(path)], m[2])
+				start = pos
+			}
+		}
+		pos = end
+	}
+	if start < len(text) {
+		sections = append(sections, markdownSection{textSpan{start, len(text), 0}, path})
+	}
+	return sections
+}
+
+func TextChunkerStrategies() ([]TextChunk, error) {
+	// [START text_chunker_strategies]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(getMedia(), "a11.txt"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Fixed windows of about 512 tokens, overlapping by 64, measured offline.
+	windows := &TextChunker{Strategy: FixedTokens, MaxTokens: 512, Overlap: 64}
+	chunks, err := windows.Split(ctx, "a11.txt", string(data))
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.Info("Fixed-size chunks", "count", len(chunks), "first_id", chunks[0].ID, "first_tokens", chunks[0].Tokens)
+
+	// Sections of a markdown document, measured exactly with CountTokens.
+	notes := "# Apollo 11\n\nThe first crewed lunar landing.\n\n" +
+		"## Launch\n\nLaunched from Kennedy Space Center on July 16, 1969.\n\n" +
+		"## Landing\n\nThe Eagle landed in the Sea of Tranquility on July 20.\n"
+	sections := &TextChunker{
+		Strategy:  MarkdownChunks,
+		MaxTokens: 256,
+		Count:     CountTokensEstimator(client),
+		Model:     "gemini-2.0-flash",
+	}
+	chunks, err = sections.Split(ctx, "notes.md", notes)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, c := range chunks {
+		slog.Info("Markdown chunk", "id", c.ID, "headings", c.Headings, "tokens", c.Tokens)
+	}
+	// [END text_chunker_strategies]
+	return chunks, err
+}
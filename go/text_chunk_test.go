@@ -0,0 +1,264 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+// checkChunks verifies that chunks are in order, match their offsets, fit
+// in maxTokens and together cover every non-space byte of text.
+func checkChunks(t *testing.T, text string, chunks []TextChunk, maxTokens int) {
+	t.Helper()
+	covered := 0
+	for i, c := range chunks {
+		if c.Index != i || text[c.Start:c.End] != c.Text {
+			t.Fatalf("chunk %d: index %d, offsets %d:%d don't match text", i, c.Index, c.Start, c.End)
+		}
+		if c.Tokens > maxTokens {
+			t.Errorf("chunk %d has %d tokens, max %d", i, c.Tokens, maxTokens)
+		}
+		if c.Start > covered && strings.TrimSpace(text[covered:c.Start]) != "" {
+			t.Fatalf("text between chunks %d and %d is missing: %q", i-1, i, text[covered:c.Start])
+		}
+		covered = max(covered, c.End)
+	}
+	if strings.TrimSpace(text[covered:]) != "" {
+		t.Fatalf("text after the last chunk is missing: %q", text[covered:])
+	}
+}
+
+func TestTextChunkerFixedTokensOverlap(t *testing.T) {
+	var words []string
+	for i := range 100 {
+		words = append(words, fmt.Sprintf("w%02d", i)) // one token with its space
+	}
+	text := strings.Join(words, " ")
+	chunker := &TextChunker{Strategy: FixedTokens, MaxTokens: 10, Overlap: 3}
+	chunks, err := chunker.Split(context.Background(), "words", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChunks(t, text, chunks, 10)
+	if len(chunks) != 14 {
+		t.Fatalf("got %d chunks, want 14", len(chunks))
+	}
+	if got := strings.Fields(chunks[1].Text); got[0] != "w07" || len(got) != 10 {
+		t.Errorf("second chunk = %v, want 10 words from w07", got)
+	}
+}
+
+func TestTextChunkerSentencesAndParagraphs(t *testing.T) {
+	text := "Roll program. Roll's complete and the pitch is programmed!\n\n" +
+		"One Bravo. Apollo 11, Houston, you're good at 1 minute. Roger.\n\n" +
+		"Stand by for mode 1 Charlie? MARK."
+	ctx := context.Background()
+
+	sentences, err := (&TextChunker{Strategy: SentenceChunks, MaxTokens: 12}).Split(ctx, "a11", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChunks(t, text, sentences, 12)
+	for _, c := range sentences {
+		if !strings.ContainsAny(c.Text[len(c.Text)-1:], ".!?") {
+			t.Errorf("chunk %q does not end a sentence", c.Text)
+		}
+	}
+
+	paragraphs, err := (&TextChunker{Strategy: ParagraphChunks, MaxTokens: 30}).Split(ctx, "a11", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChunks(t, text, paragraphs, 30)
+	want := []string{
+		"Roll program. Roll's complete and the pitch is programmed!",
+		"One Bravo. Apollo 11, Houston, you're good at 1 minute. Roger.\n\nStand by for mode 1 Charlie? MARK.",
+	}
+	var got []string
+	for _, c := range paragraphs {
+		got = append(got, c.Text)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("paragraph chunks = %q, want %q", got, want)
+	}
+
+	// Paragraphs larger than MaxTokens are cut between sentences.
+	long, err := (&TextChunker{Strategy: ParagraphChunks, MaxTokens: 14}).Split(ctx, "a11", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChunks(t, text, long, 14)
+	want = []string{
+		"Roll program.",
+		"Roll's complete and the pitch is programmed!",
+		"One Bravo.",
+		"Apollo 11, Houston, you're good at 1 minute. Roger.",
+		"Stand by for mode 1 Charlie? MARK.",
+	}
+	got = nil
+	for _, c := range long {
+		got = append(got, c.Text)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("chunks of long paragraphs = %q, want %q", got, want)
+	}
+}
+
+func TestTextChunkerMarkdown(t *testing.T) {
+	text := "Preamble.\n\n# Apollo 11\n\nIntro.\n\n## Launch\n\n```sh\n# not a heading\n```\n\n" +
+		"### Staging\n\nS-IC cutoff.\n\n## Landing ##\n\nThe Eagle has landed.\n"
+	chunks, err := (&TextChunker{Strategy: MarkdownChunks, MaxTokens: 100, Overlap: 50}).Split(context.Background(), "notes.md", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChunks(t, text, chunks, 100)
+	var got []string
+	for _, c := range chunks {
+		got = append(got, strings.Join(c.Headings, " > ")+": "+strings.SplitN(c.Text, "\n", 2)[0])
+	}
+	want := []string{
+		": Preamble.",
+		"Apollo 11: # Apollo 11",
+		"Apollo 11 > Launch: ## Launch",
+		"Apollo 11 > Launch > Staging: ### Staging",
+		"Apollo 11 > Landing: ## Landing ##",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("sections = %q, want %q", got, want)
+	}
+}
+
+func TestTextChunkerRepacksWhenCountIsHigher(t *testing.T) {
+	calls := 0
+	chunker := &TextChunker{
+		Strategy:  SentenceChunks,
+		MaxTokens: 40,
+		Model:     "gemini-2.0-flash",
+		// Twice the offline estimate, as for text in a dense script.
+		Count: func(ctx context.Context, model string, contents []*genai.Content) (int, error) {
+			calls++
+			n, _ := EstimateTokensHeuristic(ctx, model, contents)
+			return 2 * n, nil
+		},
+	}
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)
+	chunks, err := chunker.Split(context.Background(), "fox", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChunks(t, text, chunks, 40)
+	if len(chunks) < 8 || calls > 2*len(chunks) {
+		t.Errorf("%d chunks from %d count calls", len(chunks), calls)
+	}
+}
+
+func TestTextChunkerRejectsLargeOverlap(t *testing.T) {
+	for _, c := range []*TextChunker{
+		{MaxTokens: 10, Overlap: 10},
+		{MaxTokens: 10, Overlap: 50},
+		{Overlap: 512},
+		{Overlap: -1},
+	} {
+		if _, err := c.Split(context.Background(), "doc", "Roll program."); err == nil {
+			t.Errorf("MaxTokens %d, Overlap %d was accepted", c.MaxTokens, c.Overlap)
+		}
+	}
+}
+
+func TestTextChunkerHeuristics(t *testing.T) {
+	text := strings.Repeat("abcd ", 40) // 200 characters
+	ctx := context.Background()
+	def, err := (&TextChunker{Strategy: FixedTokens, MaxTokens: 10}).Split(ctx, "doc", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two characters per token raises every estimate, and with no Count the
+	// same heuristics measure the chunks.
+	dense, err := (&TextChunker{Strategy: FixedTokens, MaxTokens: 10, Heuristics: TokenHeuristics{CharsPerToken: 2}}).Split(ctx, "doc", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChunks(t, text, dense, 10)
+	if len(dense) <= len(def) {
+		t.Errorf("got %d chunks with 2 chars per token and %d with the default", len(dense), len(def))
+	}
+	if want := (TokenHeuristics{CharsPerToken: 2}).Text(dense[0].Text); dense[0].Tokens != want {
+		t.Errorf("first chunk measured %d tokens, want %d", dense[0].Tokens, want)
+	}
+}
+
+func TestTextChunkerSplitsOversizedWord(t *testing.T) {
+	// Count charges a token per rune, four times the estimate, so the one
+	// long word is too large even though it is a single unit.
+	chunker := &TextChunker{
+		Strategy:  ParagraphChunks,
+		MaxTokens: 10,
+		Count: func(ctx context.Context, model string, contents []*genai.Content) (int, error) {
+			return len([]rune(contents[0].Parts[0].Text)), nil
+		},
+	}
+	text := "Short.\n\n" + strings.Repeat("x", 35)
+	chunks, err := chunker.Split(context.Background(), "doc", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChunks(t, text, chunks, 10)
+	if len(chunks) < 5 {
+		t.Errorf("got %d chunks, want the word split into at least 4", len(chunks))
+	}
+}
+
+func TestTextChunkerStableIDs(t *testing.T) {
+	chunker := &TextChunker{Strategy: ParagraphChunks, MaxTokens: 5}
+	ctx := context.Background()
+	before, _ := chunker.Split(ctx, "log", "Roger, clock.\n\nRoger.\n\nRoll program.\n\nRoger.")
+	after, _ := chunker.Split(ctx, "log", "Roger, clock.\n\nRoger.\n\nRoll complete.\n\nRoger.")
+	if before[0].ID != after[0].ID || before[1].ID != after[1].ID || before[2].ID == after[2].ID {
+		t.Errorf("IDs before %v, after %v: only the edited chunk should change", before, after)
+	}
+	if before[3].ID == before[1].ID || !strings.HasPrefix(before[3].ID, "log#") {
+		t.Errorf("repeated chunk ID = %s, want one distinct from %s", before[3].ID, before[1].ID)
+	}
+	// Dropping the first "Roger." keeps the ID of the last one.
+	chunker.MaxTokens = 4 // one paragraph per chunk
+	dropped, _ := chunker.Split(ctx, "log", "Roger, clock.\n\nRoger.\n\nRoll program.\n\nRoger.\n\nStand by.\n\nRoger.")
+	trimmed, _ := chunker.Split(ctx, "log", "Roger, clock.\n\nRoll program.\n\nRoger.\n\nStand by.\n\nRoger.")
+	if len(dropped) != 6 || len(trimmed) != 5 {
+		t.Fatalf("got %d and %d chunks, want 6 and 5", len(dropped), len(trimmed))
+	}
+	if last, want := trimmed[len(trimmed)-1].ID, dropped[len(dropped)-1].ID; last != want {
+		t.Errorf("last repeat ID changed from %s to %s after an earlier repeat was removed", want, last)
+	}
+	other, _ := chunker.Split(ctx, "other", "Roger, clock.")
+	if other[0].ID == before[0].ID || !strings.HasPrefix(other[0].ID, "other#") {
+		t.Errorf("ID %s does not depend on the source", other[0].ID)
+	}
+}
+
+func TestTextChunkerTranscript(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(getMedia(), "a11.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, strategy := range []ChunkStrategy{FixedTokens, SentenceChunks, ParagraphChunks} {
+		chunks, err := (&TextChunker{Strategy: strategy, MaxTokens: 512, Overlap: 64}).Split(context.Background(), "a11.txt", text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkChunks(t, text, chunks, 512)
+	}
+}
+
+func TestTextChunkerStrategies(t *testing.T) {
+	_, err := TextChunkerStrategies()
+	if err != nil {
+		t.Errorf("TextChunkerStrategies returned an error: %v", err)
+	}
+}
@@ -2,11 +2,11 @@ package examples
 
 import (
 	"context"
-	"fmt"
 	"log"
-	"time"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 
 	"google.golang.org/genai"
 )
@@ -15,8 +15,9 @@ func TextGenTextOnlyPrompt() (*genai.GenerateContentResponse, error) {
 	// [START text_gen_text_only_prompt]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -37,8 +38,9 @@ func TextGenTextOnlyPromptStreaming() error {
 	// [START text_gen_text_only_prompt_streaming]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -55,7 +57,7 @@ func TextGenTextOnlyPromptStreaming() error {
 		if err != nil {
 			log.Fatal(err)
 		}
-		fmt.Print(response.Candidates[0].Content.Parts[0].Text)
+		printResponse(response)
 	}
 	// [END text_gen_text_only_prompt_streaming]
 	return err
@@ -65,8 +67,9 @@ func TextGenMultimodalOneImagePrompt() (*genai.GenerateContentResponse, error) {
 	// [START text_gen_multimodal_one_image_prompt]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -103,8 +106,9 @@ func TextGenMultimodalOneImagePromptStreaming() error {
 	// [START text_gen_multimodal_one_image_prompt_streaming]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -135,7 +139,7 @@ func TextGenMultimodalOneImagePromptStreaming() error {
 		if err != nil {
 			log.Fatal(err)
 		}
-		fmt.Print(response.Candidates[0].Content.Parts[0].Text)
+		printResponse(response)
 	}
 	// [END text_gen_multimodal_one_image_prompt_streaming]
 	return err
@@ -145,8 +149,9 @@ func TextGenMultimodalMultiImagePrompt() (*genai.GenerateContentResponse, error)
 	// [START text_gen_multimodal_multi_image_prompt]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -197,8 +202,9 @@ func TextGenMultimodalMultiImagePromptStreaming() error {
 	// [START text_gen_multimodal_multi_image_prompt_streaming]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -245,7 +251,7 @@ func TextGenMultimodalMultiImagePromptStreaming() error {
 		if err != nil {
 			log.Fatal(err)
 		}
-		fmt.Print(result.Candidates[0].Content.Parts[0].Text)
+		printResponse(result)
 	}
 	// [END text_gen_multimodal_multi_image_prompt_streaming]
 	return err
@@ -255,8 +261,9 @@ func TextGenMultimodalAudio() (*genai.GenerateContentResponse, error) {
 	// [START text_gen_multimodal_audio]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -295,8 +302,9 @@ func TextGenMultimodalAudioStreaming() error {
 	// [START text_gen_multimodal_audio_streaming]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -331,7 +339,7 @@ func TextGenMultimodalAudioStreaming() error {
 		if err != nil {
 			log.Fatal(err)
 		}
-		fmt.Print(result.Candidates[0].Content.Parts[0].Text)
+		printResponse(result)
 	}
 	// [END text_gen_multimodal_audio_streaming]
 	return err
@@ -341,8 +349,9 @@ func TextGenMultimodalVideoPrompt() (*genai.GenerateContentResponse, error) {
 	// [START text_gen_multimodal_video_prompt]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -359,16 +368,15 @@ func TextGenMultimodalVideoPrompt() (*genai.GenerateContentResponse, error) {
 		log.Fatal(err)
 	}
 
-	// Poll until the video file is completely processed (state becomes ACTIVE).
-	for file.State == genai.FileStateUnspecified || file.State != genai.FileStateActive {
-		fmt.Println("Processing video...")
-		fmt.Println("File state:", file.State)
-		time.Sleep(5 * time.Second)
-
-		file, err = client.Files.Get(ctx, file.Name, nil)
-		if err != nil {
-			log.Fatal(err)
-		}
+	// Poll with backoff until the video file is completely processed (state
+	// becomes ACTIVE), giving up after five minutes.
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	file, err = WaitForFileActive(waitCtx, client, file.Name, &WaitOptions{
+		Progress: func(f *genai.File) { slog.Info("File state", "name", f.Name, "state", f.State) },
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	parts := []*genai.Part{
@@ -393,8 +401,9 @@ func TextGenMultimodalVideoPromptStreaming() error {
 	// [START text_gen_multimodal_video_prompt_streaming]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -411,16 +420,15 @@ func TextGenMultimodalVideoPromptStreaming() error {
 		log.Fatal(err)
 	}
 
-	// Poll until the video file is completely processed (state becomes ACTIVE).
-	for file.State == genai.FileStateUnspecified || file.State != genai.FileStateActive {
-		fmt.Println("Processing video...")
-		fmt.Println("File state:", file.State)
-		time.Sleep(5 * time.Second)
-
-		file, err = client.Files.Get(ctx, file.Name, nil)
-		if err != nil {
-			log.Fatal(err)
-		}
+	// Poll with backoff until the video file is completely processed (state
+	// becomes ACTIVE), giving up after five minutes.
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	file, err = WaitForFileActive(waitCtx, client, file.Name, &WaitOptions{
+		Progress: func(f *genai.File) { slog.Info("File state", "name", f.Name, "state", f.State) },
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	parts := []*genai.Part{
@@ -441,7 +449,7 @@ func TextGenMultimodalVideoPromptStreaming() error {
 		if err != nil {
 			log.Fatal(err)
 		}
-		fmt.Print(result.Candidates[0].Content.Parts[0].Text)
+		printResponse(result)
 	}
 	// [END text_gen_multimodal_video_prompt_streaming]
 	return err
@@ -451,8 +459,9 @@ func TextGenMultimodalPdf() (*genai.GenerateContentResponse, error) {
 	// [START text_gen_multimodal_pdf]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -491,8 +500,9 @@ func TextGenMultimodalPdfStreaming() error {
 	// [START text_gen_multimodal_pdf_streaming]
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  os.Getenv("GEMINI_API_KEY"),
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -527,7 +537,7 @@ func TextGenMultimodalPdfStreaming() error {
 		if err != nil {
 			log.Fatal(err)
 		}
-		fmt.Print(result.Candidates[0].Content.Parts[0].Text)
+		printResponse(result)
 	}
 	// [END text_gen_multimodal_pdf_streaming]
 	return err
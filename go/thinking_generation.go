@@ -3,7 +3,7 @@ package examples
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"strings"
 
@@ -20,8 +20,9 @@ func newGenAIClient(ctx context.Context) (*genai.Client, error) {
 		return nil, fmt.Errorf("GEMINI_API_KEY environment variable not set")
 	}
 	return genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI, // Assuming Gemini API backend
+		APIKey:     apiKey,
+		Backend:    genai.BackendGeminiAPI, // Assuming Gemini API backend
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
 	})
 }
 
@@ -30,7 +31,7 @@ func ThinkingTextOnlyPrompt() (*genai.GenerateContentResponse, error) {
 	ctx := context.Background()
 	client, err := newGenAIClient(ctx)
 	if err != nil {
-		log.Printf("Failed to create client: %v", err)
+		slog.Error("Failed to create client", "err", err)
 		return nil, err
 	}
 
@@ -41,11 +42,11 @@ func ThinkingTextOnlyPrompt() (*genai.GenerateContentResponse, error) {
 
 	resp, err := client.Models.GenerateContent(ctx, modelID, contents, nil)
 	if err != nil {
-		log.Printf("GenerateContent failed: %v", err)
+		slog.Error("GenerateContent failed", "err", err)
 		return nil, err
 	}
 
-	fmt.Println(resp.Text())
+	printResponse(resp)
 	// [END thinking_text_only_prompt]
 	return resp, nil
 }
@@ -55,7 +56,7 @@ func ThinkingTextOnlyPromptStreaming() (string, error) {
 	ctx := context.Background()
 	client, err := newGenAIClient(ctx)
 	if err != nil {
-		log.Printf("Failed to create client: %v", err)
+		slog.Error("Failed to create client", "err", err)
 		return "", err
 	}
 
@@ -68,17 +69,16 @@ func ThinkingTextOnlyPromptStreaming() (string, error) {
 	stream := client.Models.GenerateContentStream(ctx, modelID, contents, nil)
 	for resp, err := range stream {
 		if err != nil {
-			log.Printf("Stream error: %v", err)
+			slog.Error("Stream error", "err", err)
 			return fullResponse.String(), err
 		}
+		printResponse(resp)
 		// Check if there are candidates and parts before accessing
 		if len(resp.Candidates) > 0 && len(resp.Candidates[0].Content.Parts) > 0 {
 			textPart := resp.Candidates[0].Content.Parts[0].Text
-			fmt.Print(textPart) // Print chunk directly
 			fullResponse.WriteString(textPart)
 		}
 	}
-	fmt.Println("\n" + strings.Repeat("_", 80))
 	// [END thinking_text_only_prompt_streaming]
 	return fullResponse.String(), nil
 }
@@ -88,7 +88,7 @@ func ThinkingLogicPuzzle() (*genai.GenerateContentResponse, error) {
 	ctx := context.Background()
 	client, err := newGenAIClient(ctx)
 	if err != nil {
-		log.Printf("Failed to create client: %v", err)
+		slog.Error("Failed to create client", "err", err)
 		return nil, err
 	}
 
@@ -106,11 +106,11 @@ func ThinkingLogicPuzzle() (*genai.GenerateContentResponse, error) {
 
 	resp, err := client.Models.GenerateContent(ctx, modelID, contents, nil)
 	if err != nil {
-		log.Printf("GenerateContent failed: %v", err)
+		slog.Error("GenerateContent failed", "err", err)
 		return nil, err
 	}
 
-	fmt.Println(resp.Text())
+	printResponse(resp)
 	// [END thinking_logic_puzzle]
 	return resp, nil
 }
@@ -120,7 +120,7 @@ func ThinkingCodeExplanation() (*genai.GenerateContentResponse, error) {
 	ctx := context.Background()
 	client, err := newGenAIClient(ctx)
 	if err != nil {
-		log.Printf("Failed to create client: %v", err)
+		slog.Error("Failed to create client", "err", err)
 		return nil, err
 	}
 
@@ -146,11 +146,11 @@ func ThinkingCodeExplanation() (*genai.GenerateContentResponse, error) {
 
 	resp, err := client.Models.GenerateContent(ctx, modelID, contents, nil)
 	if err != nil {
-		log.Printf("GenerateContent failed: %v", err)
+		slog.Error("GenerateContent failed", "err", err)
 		return nil, err
 	}
 
-	fmt.Println(resp.Text())
+	printResponse(resp)
 	// [END thinking_code_explanation]
 	return resp, nil
 }
@@ -160,7 +160,7 @@ func ThinkingCreativeWritingConstraints() (*genai.GenerateContentResponse, error
 	ctx := context.Background()
 	client, err := newGenAIClient(ctx)
 	if err != nil {
-		log.Printf("Failed to create client: %v", err)
+		slog.Error("Failed to create client", "err", err)
 		return nil, err
 	}
 
@@ -174,11 +174,11 @@ func ThinkingCreativeWritingConstraints() (*genai.GenerateContentResponse, error
 
 	resp, err := client.Models.GenerateContent(ctx, modelID, contents, nil)
 	if err != nil {
-		log.Printf("GenerateContent failed: %v", err)
+		slog.Error("GenerateContent failed", "err", err)
 		return nil, err
 	}
 
-	fmt.Println(resp.Text())
+	printResponse(resp)
 	// [END thinking_creative_writing_constraints]
 	return resp, nil
 }
@@ -188,7 +188,7 @@ func ThinkingWithSearchTool() (*genai.GenerateContentResponse, error) {
 	ctx := context.Background()
 	client, err := newGenAIClient(ctx)
 	if err != nil {
-		log.Printf("Failed to create client: %v", err)
+		slog.Error("Failed to create client", "err", err)
 		return nil, err
 	}
 
@@ -206,11 +206,11 @@ func ThinkingWithSearchTool() (*genai.GenerateContentResponse, error) {
 
 	resp, err := client.Models.GenerateContent(ctx, modelID, contents, config)
 	if err != nil {
-		log.Printf("GenerateContent with search tool failed: %v", err)
+		slog.Error("GenerateContent with search tool failed", "err", err)
 		return nil, err
 	}
 
-	fmt.Println(resp)
+	printResponse(resp)
 
 	// [END thinking_with_search_tool]
 	return resp, nil
@@ -221,7 +221,7 @@ func ThinkingWithSearchToolStreaming() (string, error) {
 	ctx := context.Background()
 	client, err := newGenAIClient(ctx)
 	if err != nil {
-		log.Printf("Failed to create client: %v", err)
+		slog.Error("Failed to create client", "err", err)
 		return "", err
 	}
 
@@ -243,23 +243,20 @@ func ThinkingWithSearchToolStreaming() (string, error) {
 	stream := client.Models.GenerateContentStream(ctx, modelID, contents, config)
 	for resp, err := range stream {
 		if err != nil {
-			log.Printf("Stream error: %v", err)
-			fmt.Println("\nCould not access grounding metadata from stream response likely due to error.")
+			slog.Error("Stream error; grounding metadata is unavailable", "err", err)
 			return fullResponseText.String(), err
 		}
+		printResponse(resp)
 		// Process text chunks
 		if len(resp.Candidates) > 0 && resp.Candidates[0].Content != nil && len(resp.Candidates[0].Content.Parts) > 0 {
 			textPart := resp.Candidates[0].Content.Parts[0].Text
 			if textPart != "" {
-				fmt.Print(textPart)
 				fullResponseText.WriteString(textPart)
 			}
 		}
 		// finalResponse = resp // Keep track of the latest response which might contain aggregated data
 	}
 
-	fmt.Println("\n" + strings.Repeat("_", 80)) // Separator
-
 	// [END thinking_with_search_tool_streaming]
 	return fullResponseText.String(), nil
 }
@@ -269,7 +266,7 @@ func ThinkingCodeExecution() (*genai.GenerateContentResponse, error) {
 	ctx := context.Background()
 	client, err := newGenAIClient(ctx)
 	if err != nil {
-		log.Printf("Failed to create client: %v", err)
+		slog.Error("Failed to create client", "err", err)
 		return nil, err
 	}
 
@@ -290,11 +287,11 @@ func ThinkingCodeExecution() (*genai.GenerateContentResponse, error) {
 
 	resp, err := client.Models.GenerateContent(ctx, modelID, contents, config)
 	if err != nil {
-		log.Printf("GenerateContent with code execution failed: %v", err)
+		slog.Error("GenerateContent with code execution failed", "err", err)
 		return nil, err
 	}
 
-	fmt.Println(resp)
+	printResponse(resp)
 
 	// [END thinking_code_execution]
 	return resp, nil
@@ -305,7 +302,7 @@ func ThinkingStructuredOutputJson() (*genai.GenerateContentResponse, error) {
 	ctx := context.Background()
 	client, err := newGenAIClient(ctx)
 	if err != nil {
-		log.Printf("Failed to create client: %v", err)
+		slog.Error("Failed to create client", "err", err)
 		return nil, err
 	}
 
@@ -334,11 +331,11 @@ func ThinkingStructuredOutputJson() (*genai.GenerateContentResponse, error) {
 	// resp, err := client.Models.GenerateContent(ctx, modelID, contents, config)
 
 	if err != nil {
-		log.Printf("GenerateContent failed: %v", err)
+		slog.Error("GenerateContent failed", "err", err)
 		return nil, err
 	}
 
-	fmt.Println(resp.Text())
+	printResponse(resp)
 	// [END thinking_structured_output_json]
 	return resp, nil
 }
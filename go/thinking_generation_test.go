@@ -1,12 +1,12 @@
 package examples
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 	"testing"
-	"time"
 	// Ensure this import is correct
 )
 
@@ -22,17 +22,24 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
-// Helper sleep function for potential rate limits
-func sleep(d time.Duration) {
-	time.Sleep(d)
-}
+// testLimiter keeps the thinking tests within the model's free-tier quota
+var testLimiter = NewRateLimiter(map[string]RateLimit{
+	modelID: {RequestsPerMinute: 5},
+}, RateLimit{})
 
-const testDelay = 1 * time.Second // Delay between tests
+// Helper that blocks until the next test may call the model
+func waitForQuota(t *testing.T) {
+	t.Helper()
+	if _, err := testLimiter.Wait(context.Background(), modelID, 0); err != nil {
+		t.Fatalf("waiting for rate limit: %v", err)
+	}
+}
 
 func TestThinkingTextOnlyPrompt(t *testing.T) {
 	if os.Getenv("GEMINI_API_KEY") == "" {
 		t.Skip("GEMINI_API_KEY not set")
 	}
+	waitForQuota(t)
 	resp, err := ThinkingTextOnlyPrompt()
 	if err != nil {
 		t.Fatalf("ThinkingTextOnlyPrompt failed: %v", err)
@@ -47,13 +54,13 @@ func TestThinkingTextOnlyPrompt(t *testing.T) {
 	} else {
 		t.Logf("Text: %s", text)
 	}
-	sleep(testDelay)
 }
 
 func TestThinkingTextOnlyPromptStreaming(t *testing.T) {
 	if os.Getenv("GEMINI_API_KEY") == "" {
 		t.Skip("GEMINI_API_KEY not set")
 	}
+	waitForQuota(t)
 	// This function returns (string, error) directly
 	fullResp, err := ThinkingTextOnlyPromptStreaming()
 	if err != nil {
@@ -69,13 +76,13 @@ func TestThinkingTextOnlyPromptStreaming(t *testing.T) {
 	if err == nil && fullResp == "" {
 		t.Error("ThinkingTextOnlyPromptStreaming returned empty response without error")
 	}
-	sleep(testDelay)
 }
 
 func TestThinkingLogicPuzzle(t *testing.T) {
 	if os.Getenv("GEMINI_API_KEY") == "" {
 		t.Skip("GEMINI_API_KEY not set")
 	}
+	waitForQuota(t)
 	resp, err := ThinkingLogicPuzzle()
 	if err != nil {
 		t.Fatalf("ThinkingLogicPuzzle failed: %v", err)
@@ -90,13 +97,13 @@ func TestThinkingLogicPuzzle(t *testing.T) {
 	} else {
 		t.Logf("Text: %s", text)
 	}
-	sleep(testDelay)
 }
 
 func TestThinkingCodeExplanation(t *testing.T) {
 	if os.Getenv("GEMINI_API_KEY") == "" {
 		t.Skip("GEMINI_API_KEY not set")
 	}
+	waitForQuota(t)
 	resp, err := ThinkingCodeExplanation()
 	if err != nil {
 		t.Fatalf("ThinkingCodeExplanation failed: %v", err)
@@ -111,13 +118,13 @@ func TestThinkingCodeExplanation(t *testing.T) {
 	} else {
 		t.Logf("Text: %s", text)
 	}
-	sleep(testDelay)
 }
 
 func TestThinkingCreativeWritingConstraints(t *testing.T) {
 	if os.Getenv("GEMINI_API_KEY") == "" {
 		t.Skip("GEMINI_API_KEY not set")
 	}
+	waitForQuota(t)
 	resp, err := ThinkingCreativeWritingConstraints()
 	if err != nil {
 		t.Fatalf("ThinkingCreativeWritingConstraints failed: %v", err)
@@ -136,13 +143,13 @@ func TestThinkingCreativeWritingConstraints(t *testing.T) {
 	// if strings.Contains(strings.ToLower(text), "e") {
 	// 	t.Errorf("Constraint check failed: Result contains 'e'")
 	// }
-	sleep(testDelay)
 }
 
 func TestThinkingWithSearchTool(t *testing.T) {
 	if os.Getenv("GEMINI_API_KEY") == "" {
 		t.Skip("GEMINI_API_KEY not set")
 	}
+	waitForQuota(t)
 	// t.Setenv("GOOGLE_API_KEY", os.Getenv("GEMINI_API_KEY")) // May not be needed depending on auth flow
 
 	resp, err := ThinkingWithSearchTool()
@@ -163,14 +170,13 @@ func TestThinkingWithSearchTool(t *testing.T) {
 		}
 	}
 	// A more robust test could check resp.Candidates[0].GroundingMetadata != nil
-
-	sleep(testDelay)
 }
 
 func TestThinkingWithSearchToolStreaming(t *testing.T) {
 	if os.Getenv("GEMINI_API_KEY") == "" {
 		t.Skip("GEMINI_API_KEY not set")
 	}
+	waitForQuota(t)
 	// t.Setenv("GOOGLE_API_KEY", os.Getenv("GEMINI_API_KEY"))
 
 	// This function returns (string, error) directly
@@ -183,14 +189,13 @@ func TestThinkingWithSearchToolStreaming(t *testing.T) {
 	if err == nil && fullResp == "" {
 		t.Error("ThinkingWithSearchToolStreaming completed successfully but returned empty response")
 	}
-
-	sleep(testDelay)
 }
 
 func TestThinkingCodeExecution(t *testing.T) {
 	if os.Getenv("GEMINI_API_KEY") == "" {
 		t.Skip("GEMINI_API_KEY not set")
 	}
+	waitForQuota(t)
 	// t.Setenv("GOOGLE_API_KEY", os.Getenv("GEMINI_API_KEY"))
 
 	resp, err := ThinkingCodeExecution()
@@ -224,14 +229,13 @@ func TestThinkingCodeExecution(t *testing.T) {
 		t.Error("ThinkingCodeExecution returned response with no candidates")
 	}
 	t.Logf("Found Code Execution/Result Part: %t", foundToolPart)
-
-	sleep(testDelay)
 }
 
 func TestThinkingStructuredOutputJson(t *testing.T) {
 	if os.Getenv("GEMINI_API_KEY") == "" {
 		t.Skip("GEMINI_API_KEY not set")
 	}
+	waitForQuota(t)
 	resp, err := ThinkingStructuredOutputJson()
 	if err != nil {
 		t.Fatalf("ThinkingStructuredOutputJson failed: %v", err)
@@ -268,5 +272,4 @@ func TestThinkingStructuredOutputJson(t *testing.T) {
 			t.Errorf("Failed to parse response text as JSON in test: %v\nResponse text was:\n%s", parseErr, responseText)
 		}
 	}
-	sleep(testDelay)
 }
@@ -0,0 +1,400 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/genai"
+)
+
+// TokenHeuristics approximates token counts without calling CountTokens.
+// Zero fields take their value from DefaultTokenHeuristics.
+type TokenHeuristics struct {
+	// CharsPerToken is the average number of characters in a text token.
+	CharsPerToken float64
+	// Images with both sides at most SmallImageMaxDim pixels cost one tile.
+	// Larger images are cut into ImageTileSize squares of TokensPerImageTile
+	// each, up to MaxImageTiles tiles. Zero MaxImageTiles means no limit.
+	SmallImageMaxDim   int
+	ImageTileSize      int
+	TokensPerImageTile int
+	MaxImageTiles      int
+	// VideoTokensPerSecond includes the audio track. Video is sampled once a
+	// second, so a started second is charged in full.
+	VideoTokensPerSecond float64
+	AudioTokensPerSecond float64
+	PDFTokensPerPage     int
+	// UnknownMediaTokens is charged for media whose size or length can't be
+	// read locally, such as File API references.
+	UnknownMediaTokens int
+}
+
+// DefaultTokenHeuristics returns the rates documented for Gemini 2.0 models.
+func DefaultTokenHeuristics() TokenHeuristics {
+	return TokenHeuristics{
+		CharsPerToken:        4,
+		SmallImageMaxDim:     384,
+		ImageTileSize:        768,
+		TokensPerImageTile:   258,
+		VideoTokensPerSecond: 263,
+		AudioTokensPerSecond: 32,
+		PDFTokensPerPage:     258,
+		UnknownMediaTokens:   258,
+	}
+}
+
+func (h TokenHeuristics) withDefaults() TokenHeuristics {
+	d := DefaultTokenHeuristics()
+	if h.CharsPerToken <= 0 {
+		h.CharsPerToken = d.CharsPerToken
+	}
+	if h.SmallImageMaxDim == 0 {
+		h.SmallImageMaxDim = d.SmallImageMaxDim
+	}
+	if h.ImageTileSize <= 0 {
+		h.ImageTileSize = d.ImageTileSize
+	}
+	if h.TokensPerImageTile == 0 {
+		h.TokensPerImageTile = d.TokensPerImageTile
+	}
+	if h.VideoTokensPerSecond == 0 {
+		h.VideoTokensPerSecond = d.VideoTokensPerSecond
+	}
+	if h.AudioTokensPerSecond == 0 {
+		h.AudioTokensPerSecond = d.AudioTokensPerSecond
+	}
+	if h.PDFTokensPerPage == 0 {
+		h.PDFTokensPerPage = d.PDFTokensPerPage
+	}
+	if h.UnknownMediaTokens == 0 {
+		h.UnknownMediaTokens = d.UnknownMediaTokens
+	}
+	return h
+}
+
+// Text estimates the tokens in s.
+func (h TokenHeuristics) Text(s string) int {
+	h = h.withDefaults()
+	return int(math.Ceil(float64(utf8.RuneCountInString(s)) / h.CharsPerToken))
+}
+
+// Image estimates the tokens for an image of the given size.
+func (h TokenHeuristics) Image(width, height int) int {
+	h = h.withDefaults()
+	if width <= h.SmallImageMaxDim && height <= h.SmallImageMaxDim {
+		return h.TokensPerImageTile
+	}
+	tiles := ceilDiv(width, h.ImageTileSize) * ceilDiv(height, h.ImageTileSize)
+	if h.MaxImageTiles > 0 {
+		tiles = min(tiles, h.MaxImageTiles)
+	}
+	return tiles * h.TokensPerImageTile
+}
+
+// Video estimates the tokens for a video of length d.
+func (h TokenHeuristics) Video(d time.Duration) int {
+	h = h.withDefaults()
+	return int(math.Ceil(math.Ceil(d.Seconds()) * h.VideoTokensPerSecond))
+}
+
+// Audio estimates the tokens for audio of length d.
+func (h TokenHeuristics) Audio(d time.Duration) int {
+	h = h.withDefaults()
+	return int(math.Ceil(d.Seconds() * h.AudioTokensPerSecond))
+}
+
+// PDF estimates the tokens for a document with the given number of pages.
+func (h TokenHeuristics) PDF(pages int) int {
+	h = h.withDefaults()
+	return pages * h.PDFTokensPerPage
+}
+
+// Media estimates the tokens for inline data by reading its dimensions,
+// duration or page count. Supported formats are JPEG, PNG, GIF, MP4, WAV,
+// text/* and PDFs that CountPDFPages can read.
+func (h TokenHeuristics) Media(mimeType string, data []byte) (int, error) {
+	switch {
+	case strings.HasPrefix(mimeType, "text/"):
+		return h.Text(string(data)), nil
+	case strings.HasPrefix(mimeType, "image/"):
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return 0, fmt.Errorf("estimate tokens: %w", err)
+		}
+		return h.Image(cfg.Width, cfg.Height), nil
+	case mimeType == "application/pdf":
+		pages, err := CountPDFPages(data)
+		if err != nil {
+			return 0, fmt.Errorf("estimate tokens: %w", err)
+		}
+		return h.PDF(pages), nil
+	case mimeType == "video/mp4":
+		d, err := mp4Duration(data)
+		if err != nil {
+			return 0, fmt.Errorf("estimate tokens: %w", err)
+		}
+		return h.Video(d), nil
+	case mimeType == "audio/wav", mimeType == "audio/x-wav":
+		d, err := wavDuration(data)
+		if err != nil {
+			return 0, fmt.Errorf("estimate tokens: %w", err)
+		}
+		return h.Audio(d), nil
+	}
+	return 0, fmt.Errorf("estimate tokens: unsupported MIME type %q", mimeType)
+}
+
+// Contents estimates the prompt tokens of contents. Media that can't be
+// inspected is charged UnknownMediaTokens.
+func (h TokenHeuristics) Contents(contents []*genai.Content) int {
+	h = h.withDefaults()
+	n := 0
+	for _, c := range contents {
+		if c == nil {
+			continue
+		}
+		for _, p := range c.Parts {
+			switch {
+			case p == nil:
+			case p.Text != "":
+				n += h.Text(p.Text)
+			case p.InlineData != nil:
+				t, err := h.Media(p.InlineData.MIMEType, p.InlineData.Data)
+				if err != nil {
+					t = h.UnknownMediaTokens
+				}
+				n += t
+			case p.FileData != nil:
+				n += h.UnknownMediaTokens
+			}
+		}
+	}
+	return n
+}
+
+// Estimator adapts h to a TokenEstimator for use with RateLimiter.
+func (h TokenHeuristics) Estimator() TokenEstimator {
+	return func(_ context.Context, _ string, contents []*genai.Content) (int, error) {
+		return h.Contents(contents), nil
+	}
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+// mp4Duration reads the duration from the movie header (moov/mvhd).
+func mp4Duration(data []byte) (time.Duration, error) {
+	moov, ok := mp4Box(data, "moov")
+	if !ok {
+		return 0, errors.New("mp4: no moov box")
+	}
+	mvhd, ok := mp4Box(moov, "mvhd")
+	if !ok || len(mvhd) < 20 {
+		return 0, errors.New("mp4: no mvhd box")
+	}
+	var timescale uint32
+	var duration uint64
+	if mvhd[0] == 1 {
+		if len(mvhd) < 32 {
+			return 0, errors.New("mp4: short mvhd box")
+		}
+		timescale = binary.BigEndian.Uint32(mvhd[20:])
+		duration = binary.BigEndian.Uint64(mvhd[24:])
+	} else {
+		timescale = binary.BigEndian.Uint32(mvhd[12:])
+		duration = uint64(binary.BigEndian.Uint32(mvhd[16:]))
+	}
+	if timescale == 0 {
+		return 0, errors.New("mp4: zero timescale")
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second)), nil
+}
+
+// mp4Box returns the payload of the first box of the given type in data.
+func mp4Box(data []byte, typ string) ([]byte, bool) {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data))
+		header := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return nil, false
+			}
+			size = binary.BigEndian.Uint64(data[8:])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return nil, false
+		}
+		if string(data[4:8]) == typ {
+			return data[header:size], true
+		}
+		data = data[size:]
+	}
+	return nil, false
+}
+
+// wavDuration reads the byte rate and data length from a RIFF/WAVE header.
+func wavDuration(data []byte) (time.Duration, error) {
+	w, err := readWAV(data)
+	if err != nil {
+		return 0, err
+	}
+	if w.byteRate == 0 {
+		return 0, errors.New("wav: zero byte rate")
+	}
+	return time.Duration(float64(len(w.data)) / float64(w.byteRate) * float64(time.Second)), nil
+}
+
+// CalibrationSample compares an offline estimate with CountTokens.
+type CalibrationSample struct {
+	Name     string
+	Estimate int
+	Actual   int
+}
+
+// RelativeError is (Estimate-Actual)/Actual; positive means overestimate.
+func (s CalibrationSample) RelativeError() float64 {
+	if s.Actual == 0 {
+		return 0
+	}
+	return float64(s.Estimate-s.Actual) / float64(s.Actual)
+}
+
+// CalibrationReport summarizes the error of a set of samples.
+type CalibrationReport struct {
+	Samples []CalibrationSample
+	// MinError and MaxError bound the relative error; MeanAbsError is the
+	// average of its magnitude.
+	MinError, MaxError, MeanAbsError float64
+}
+
+// NewCalibrationReport computes error bounds over samples.
+func NewCalibrationReport(samples []CalibrationSample) CalibrationReport {
+	r := CalibrationReport{Samples: samples}
+	for i, s := range samples {
+		e := s.RelativeError()
+		if i == 0 || e < r.MinError {
+			r.MinError = e
+		}
+		if i == 0 || e > r.MaxError {
+			r.MaxError = e
+		}
+		r.MeanAbsError += math.Abs(e)
+	}
+	if len(samples) > 0 {
+		r.MeanAbsError /= float64(len(samples))
+	}
+	return r
+}
+
+// Calibrate estimates each file offline, counts it with CountTokens as inline
+// data and reports how far apart they are.
+func (h TokenHeuristics) Calibrate(ctx context.Context, client *genai.Client, model string, paths []string) (CalibrationReport, error) {
+	var samples []CalibrationSample
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return CalibrationReport{}, fmt.Errorf("calibrate: %w", err)
+		}
+		mimeType := mediaTypeByExtension(path)
+		est, err := h.Media(mimeType, data)
+		if err != nil {
+			return CalibrationReport{}, fmt.Errorf("calibrate %s: %w", path, err)
+		}
+		part := &genai.Part{InlineData: &genai.Blob{MIMEType: mimeType, Data: data}}
+		if strings.HasPrefix(mimeType, "text/") {
+			part = genai.NewPartFromText(string(data))
+		}
+		contents := []*genai.Content{genai.NewContentFromParts([]*genai.Part{part}, "user")}
+		resp, err := client.Models.CountTokens(ctx, model, contents, nil)
+		if err != nil {
+			return CalibrationReport{}, fmt.Errorf("calibrate %s: %w", path, err)
+		}
+		samples = append(samples, CalibrationSample{
+			Name:     filepath.Base(path),
+			Estimate: est,
+			Actual:   int(resp.TotalTokens),
+		})
+	}
+	return NewCalibrationReport(samples), nil
+}
+
+// mediaTypeByExtension maps the extensions of the example media to MIME types.
+func mediaTypeByExtension(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt":
+		return "text/plain"
+	case ".md":
+		return "text/markdown"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	case ".gif":
+		return "image/gif"
+	case ".pdf":
+		return "application/pdf"
+	case ".mp4":
+		return "video/mp4"
+	case ".wav":
+		return "audio/wav"
+	case ".mp3":
+		return "audio/mp3"
+	case ".mov":
+		return "video/quicktime"
+	case ".webp":
+		return "image/webp"
+	}
+	return "application/octet-stream"
+}
+
+func TokensOfflineEstimate() error {
+	// [START tokens_offline_estimate]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	h := DefaultTokenHeuristics()
+	slog.Info("Estimated text tokens", "tokens", h.Text("The quick brown fox jumps over the lazy dog."))
+
+	// Compare the offline estimates for the example media with CountTokens.
+	var paths []string
+	for _, name := range []string{"poem.txt", "a11.txt", "organ.jpg", "Cajun_instruments.jpg", "test.pdf", "Big_Buck_Bunny.mp4"} {
+		paths = append(paths, filepath.Join(getMedia(), name))
+	}
+	report, err := h.Calibrate(ctx, client, "gemini-2.0-flash", paths)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, s := range report.Samples {
+		slog.Info("Calibration sample", "name", s.Name, "estimate", s.Estimate, "actual", s.Actual, "relative_error", s.RelativeError())
+	}
+	slog.Info("Calibration", "min_error", report.MinError, "max_error", report.MaxError, "mean_abs_error", report.MeanAbsError)
+	// [END tokens_offline_estimate]
+	return err
+}
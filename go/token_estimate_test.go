@@ -0,0 +1,196 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestTokenHeuristicsImage(t *testing.T) {
+	h := DefaultTokenHeuristics()
+	for _, tc := range []struct{ w, h, want int }{
+		{384, 384, 258},
+		{385, 100, 258},
+		{1024, 768, 2 * 258},
+		{1600, 1600, 9 * 258},
+	} {
+		if got := h.Image(tc.w, tc.h); got != tc.want {
+			t.Errorf("Image(%d, %d) = %d, want %d", tc.w, tc.h, got, tc.want)
+		}
+	}
+	h.MaxImageTiles = 1
+	if got := h.Image(1600, 1600); got != 258 {
+		t.Errorf("Image(1600, 1600) capped at one tile = %d, want 258", got)
+	}
+}
+
+func TestTokenHeuristicsZeroValue(t *testing.T) {
+	var zero TokenHeuristics
+	d := DefaultTokenHeuristics()
+	if got, want := zero.Text("The quick brown fox"), d.Text("The quick brown fox"); got != want {
+		t.Errorf("Text = %d, want %d", got, want)
+	}
+	if got, want := zero.Image(2000, 1000), d.Image(2000, 1000); got != want {
+		t.Errorf("Image = %d, want %d", got, want)
+	}
+	if got, want := zero.Video(3*time.Second), d.Video(3*time.Second); got != want {
+		t.Errorf("Video = %d, want %d", got, want)
+	}
+}
+
+// TestTokenHeuristicsRecordedCounts checks the default heuristics against
+// CountTokens results recorded in testdata.
+func TestTokenHeuristicsRecordedCounts(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "count_tokens.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recorded struct {
+		Samples []struct {
+			Name     string
+			Contents []struct {
+				Role  string
+				Parts []struct{ Text, File string }
+			}
+			TotalTokens int
+		}
+	}
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		t.Fatal(err)
+	}
+
+	h := DefaultTokenHeuristics()
+	var samples []CalibrationSample
+	for _, s := range recorded.Samples {
+		var contents []*genai.Content
+		for _, c := range s.Contents {
+			var parts []*genai.Part
+			for _, p := range c.Parts {
+				if p.File == "" {
+					parts = append(parts, genai.NewPartFromText(p.Text))
+					continue
+				}
+				media, err := os.ReadFile(filepath.Join(getMedia(), p.File))
+				if err != nil {
+					t.Fatal(err)
+				}
+				parts = append(parts, genai.NewPartFromBytes(media, mediaTypeByExtension(p.File)))
+			}
+			contents = append(contents, genai.NewContentFromParts(parts, genai.Role(c.Role)))
+		}
+		samples = append(samples, CalibrationSample{Name: s.Name, Estimate: h.Contents(contents), Actual: s.TotalTokens})
+	}
+
+	report := NewCalibrationReport(samples)
+	for _, s := range report.Samples {
+		if e := s.RelativeError(); e < -0.35 || e > 0.35 {
+			t.Errorf("%s: estimate %d, recorded %d (%+.0f%%)", s.Name, s.Estimate, s.Actual, 100*e)
+		}
+	}
+	if report.MeanAbsError > 0.2 {
+		t.Errorf("mean absolute error = %.2f, want at most 0.2", report.MeanAbsError)
+	}
+}
+
+func TestTokenHeuristicsMediaFiles(t *testing.T) {
+	h := DefaultTokenHeuristics()
+	for _, name := range []string{"organ.jpg", "test.pdf", "Big_Buck_Bunny.mp4", "poem.txt"} {
+		data, err := os.ReadFile(filepath.Join(getMedia(), name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := h.Media(mediaTypeByExtension(name), data)
+		if err != nil {
+			t.Errorf("Media(%s): %v", name, err)
+		}
+		if got <= 0 {
+			t.Errorf("Media(%s) = %d, want > 0", name, got)
+		}
+	}
+}
+
+// testWAV builds a mono 16-bit PCM WAV of the given length.
+func testWAV(rate int, d time.Duration) []byte {
+	n := int(d.Seconds() * float64(rate) * 2)
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+n))
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, binary.LittleEndian, struct {
+		Size                      uint32
+		Format, Channels          uint16
+		SampleRate, ByteRate      uint32
+		BlockAlign, BitsPerSample uint16
+	}{16, 1, 1, uint32(rate), uint32(rate * 2), 2, 16})
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(n))
+	b.Write(make([]byte, n))
+	return b.Bytes()
+}
+
+func TestTokenHeuristicsAudio(t *testing.T) {
+	h := DefaultTokenHeuristics()
+	got, err := h.Media("audio/wav", testWAV(8000, 10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 320 {
+		t.Errorf("Media(wav) = %d, want 320", got)
+	}
+}
+
+func TestMP4Duration(t *testing.T) {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:], 2500)
+	box := func(typ string, payload []byte) []byte {
+		b := binary.BigEndian.AppendUint32(nil, uint32(8+len(payload)))
+		return append(append(b, typ...), payload...)
+	}
+	data := append(box("ftyp", []byte("isom")), box("moov", box("mvhd", mvhd))...)
+	got, err := mp4Duration(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 2500*time.Millisecond {
+		t.Errorf("mp4Duration = %v, want 2.5s", got)
+	}
+}
+
+func TestTokenHeuristicsCalibrate(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Contents []*genai.Content `json:"contents"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		// Pretend text costs twice the estimate.
+		tokens := 2 * DefaultTokenHeuristics().Contents(req.Contents)
+		writeJSON(w, http.StatusOK, map[string]any{"totalTokens": tokens})
+	}))
+	paths := []string{filepath.Join(getMedia(), "poem.txt")}
+	report, err := DefaultTokenHeuristics().Calibrate(context.Background(), client, "gemini-2.0-flash", paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Samples) != 1 || report.Samples[0].Name != "poem.txt" {
+		t.Fatalf("Samples = %+v", report.Samples)
+	}
+	if report.MinError != -0.5 || report.MaxError != -0.5 || report.MeanAbsError != 0.5 {
+		t.Errorf("report = %+v, want -50%% error", report)
+	}
+}
+
+func TestTokensOfflineEstimate(t *testing.T) {
+	err := TokensOfflineEstimate()
+	if err != nil {
+		t.Errorf("TokensOfflineEstimate returned an error: %v", err)
+	}
+}
@@ -0,0 +1,356 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// UploadIndexEntry remembers where a local file's content was uploaded.
+type UploadIndexEntry struct {
+	Name           string    `json:"name"`
+	URI            string    `json:"uri"`
+	MIMEType       string    `json:"mimeType"`
+	ExpirationTime time.Time `json:"expirationTime"`
+}
+
+// FileUploader uploads content once and reuses the uploaded genai.File on
+// later calls with the same bytes. It looks first in a local index keyed by
+// SHA-256, then in the remote file list, and uploads only if neither has a
+// live copy. It is safe for concurrent use; concurrent calls with the same
+// content share a single lookup and upload.
+type FileUploader struct {
+	Client *genai.Client
+	// IndexPath is where the local index is stored as JSON. If empty, the
+	// index lives only in memory.
+	IndexPath string
+	// MinRemaining is how long a reused file must still have before it
+	// expires. Zero means one hour.
+	MinRemaining time.Duration
+
+	mu       sync.Mutex
+	index    map[string]UploadIndexEntry
+	loaded   bool
+	inflight map[string]*uploadCall
+	now      func() time.Time
+}
+
+// uploadCall is a lookup and upload in progress for one content hash.
+type uploadCall struct {
+	done chan struct{}
+	file *genai.File
+	err  error
+}
+
+// NewFileUploader returns an uploader that keeps its index at indexPath.
+func NewFileUploader(client *genai.Client, indexPath string) *FileUploader {
+	return &FileUploader{Client: client, IndexPath: indexPath}
+}
+
+// DefaultUploadIndexPath returns a per-user location for the upload index.
+func DefaultUploadIndexPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "gemini-api-examples", "uploads.json")
+}
+
+// UploadFromPath uploads the file at path unless its content is already
+// available. The MIME type is taken from config or detected from the content.
+func (u *FileUploader) UploadFromPath(ctx context.Context, path string, config *genai.UploadFileConfig) (*genai.File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("upload: %w", err)
+	}
+	cfg := genai.UploadFileConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.MIMEType == "" {
+		cfg.MIMEType = normalizeMIMEType(DetectMIMEType(path, data))
+	}
+	return u.UploadBytes(ctx, data, &cfg)
+}
+
+// Upload reads r to the end and uploads its content unless it is already
+// available.
+func (u *FileUploader) Upload(ctx context.Context, r io.Reader, config *genai.UploadFileConfig) (*genai.File, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("upload: %w", err)
+	}
+	return u.UploadBytes(ctx, data, config)
+}
+
+// UploadBytes uploads data unless it is already available. Files uploaded
+// without a display name are labelled "sha256-<hex>" so that they can be
+// found again from another machine.
+func (u *FileUploader) UploadBytes(ctx context.Context, data []byte, config *genai.UploadFileConfig) (*genai.File, error) {
+	sum := sha256.Sum256(data)
+	key := hex.EncodeToString(sum[:])
+
+	for {
+		u.mu.Lock()
+		c, ok := u.inflight[key]
+		if !ok {
+			c = &uploadCall{done: make(chan struct{})}
+			if u.inflight == nil {
+				u.inflight = make(map[string]*uploadCall)
+			}
+			u.inflight[key] = c
+			u.mu.Unlock()
+
+			c.file, c.err = u.upload(ctx, key, sum[:], data, config)
+			u.mu.Lock()
+			delete(u.inflight, key)
+			u.mu.Unlock()
+			close(c.done)
+			return c.file, c.err
+		}
+		u.mu.Unlock()
+
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("upload: %w", ctx.Err())
+		}
+		// A call that ended with its own context's error says nothing about
+		// this one; try again.
+		if errors.Is(c.err, context.Canceled) || errors.Is(c.err, context.DeadlineExceeded) {
+			continue
+		}
+		return c.file, c.err
+	}
+}
+
+// upload returns the live file for key, uploading data if there is none.
+func (u *FileUploader) upload(ctx context.Context, key string, sum, data []byte, config *genai.UploadFileConfig) (*genai.File, error) {
+	if f, err := u.fromIndex(ctx, key); err != nil || f != nil {
+		return f, err
+	}
+	f, err := u.findRemote(ctx, sum)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		cfg := genai.UploadFileConfig{}
+		if config != nil {
+			cfg = *config
+		}
+		if cfg.DisplayName == "" {
+			cfg.DisplayName = "sha256-" + key
+		}
+		f, err = u.Client.Files.Upload(ctx, bytes.NewReader(data), &cfg)
+		if err != nil {
+			return nil, fmt.Errorf("upload: %w", err)
+		}
+	}
+	if err := u.remember(key, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// fromIndex returns the indexed file for key if it still exists remotely.
+func (u *FileUploader) fromIndex(ctx context.Context, key string) (*genai.File, error) {
+	u.mu.Lock()
+	if err := u.loadLocked(); err != nil {
+		u.mu.Unlock()
+		return nil, err
+	}
+	entry, ok := u.index[key]
+	u.mu.Unlock()
+	if !ok || !u.fresh(entry.ExpirationTime) {
+		return nil, nil
+	}
+
+	f, err := u.Client.Files.Get(ctx, entry.Name, nil)
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return nil, u.forget(key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("upload: get %s: %w", entry.Name, err)
+	}
+	if !u.usable(f) {
+		return nil, u.forget(key)
+	}
+	return f, nil
+}
+
+// findRemote pages through the project's files for a live one with the same hash.
+func (u *FileUploader) findRemote(ctx context.Context, sum []byte) (*genai.File, error) {
+	label := "sha256-" + hex.EncodeToString(sum)
+	for f, err := range u.Client.Files.All(ctx) {
+		if err != nil {
+			return nil, fmt.Errorf("upload: list files: %w", err)
+		}
+		match := sameSHA256(f.Sha256Hash, sum) || (f.Sha256Hash == "" && f.DisplayName == label)
+		if match && u.usable(f) {
+			return f, nil
+		}
+	}
+	return nil, nil
+}
+
+// usable reports whether f can be referenced in a request.
+func (u *FileUploader) usable(f *genai.File) bool {
+	return f.State != genai.FileStateFailed && (f.ExpirationTime.IsZero() || u.fresh(f.ExpirationTime))
+}
+
+func (u *FileUploader) fresh(expires time.Time) bool {
+	margin := u.MinRemaining
+	if margin == 0 {
+		margin = time.Hour
+	}
+	return u.clock().Add(margin).Before(expires)
+}
+
+func (u *FileUploader) clock() time.Time {
+	if u.now != nil {
+		return u.now()
+	}
+	return time.Now()
+}
+
+// sameSHA256 compares a File's Sha256Hash with sum. The API encodes the hash
+// in base64, either of the raw digest or of its hex string.
+func sameSHA256(fileHash string, sum []byte) bool {
+	b, err := base64.StdEncoding.DecodeString(fileHash)
+	if err != nil || len(b) == 0 {
+		return false
+	}
+	if len(b) == sha256.Size {
+		return bytes.Equal(b, sum)
+	}
+	return strings.EqualFold(string(b), hex.EncodeToString(sum))
+}
+
+func (u *FileUploader) remember(key string, f *genai.File) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.index[key] = UploadIndexEntry{
+		Name:           f.Name,
+		URI:            f.URI,
+		MIMEType:       f.MIMEType,
+		ExpirationTime: f.ExpirationTime,
+	}
+	return u.saveLocked()
+}
+
+func (u *FileUploader) forget(key string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.index, key)
+	return u.saveLocked()
+}
+
+func (u *FileUploader) loadLocked() error {
+	if u.loaded {
+		return nil
+	}
+	u.index = make(map[string]UploadIndexEntry)
+	u.loaded = true
+	if u.IndexPath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(u.IndexPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("upload index: %w", err)
+	}
+	if err := json.Unmarshal(data, &u.index); err != nil {
+		return fmt.Errorf("upload index %s: %w", u.IndexPath, err)
+	}
+	return nil
+}
+
+// saveLocked writes the index atomically so an interrupted run can't corrupt
+// it. Each write goes through its own temporary file, so processes sharing
+// the index don't clobber each other's partial writes.
+func (u *FileUploader) saveLocked() error {
+	if u.IndexPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(u.index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("upload index: %w", err)
+	}
+	dir := filepath.Dir(u.IndexPath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("upload index: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(u.IndexPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("upload index: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("upload index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("upload index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), u.IndexPath); err != nil {
+		return fmt.Errorf("upload index: %w", err)
+	}
+	return nil
+}
+
+func FilesCreateDeduplicated() (*genai.GenerateContentResponse, error) {
+	// [START files_create_deduplicated]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Running this example again reuses the uploaded image instead of
+	// uploading it a second time.
+	uploader := NewFileUploader(client, DefaultUploadIndexPath())
+	myfile, err := uploader.UploadFromPath(ctx, filepath.Join(getMedia(), "Cajun_instruments.jpg"), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.Info("File uploaded", "name", myfile.Name, "uri", myfile.URI)
+
+	parts := []*genai.Part{
+		genai.NewPartFromURI(myfile.URI, myfile.MIMEType),
+		genai.NewPartFromText("Can you tell me about the instruments in this photo?"),
+	}
+	contents := []*genai.Content{
+		genai.NewContentFromParts(parts, "user"),
+	}
+
+	response, err := client.Models.GenerateContent(ctx, "gemini-2.0-flash", contents, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printResponse(response)
+	// [END files_create_deduplicated]
+	return response, err
+}
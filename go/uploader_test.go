@@ -0,0 +1,166 @@
+package examples
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFileUploaderReusesIndexedUpload(t *testing.T) {
+	files := newFakeFiles()
+	client := newTestClient(t, files)
+	index := filepath.Join(t.TempDir(), "index.json")
+	path := writeTempFile(t, "poem.txt", "roses are red")
+	ctx := context.Background()
+
+	first, err := NewFileUploader(client, index).UploadFromPath(ctx, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.MIMEType != "text/plain; charset=utf-8" && first.MIMEType != "text/plain" {
+		t.Errorf("MIMEType = %q", first.MIMEType)
+	}
+	// A second run with a fresh uploader finds the file through the index.
+	second, err := NewFileUploader(client, index).UploadFromPath(ctx, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Name != first.Name || files.uploadCount() != 1 {
+		t.Errorf("got %s after %d uploads, want reuse of %s", second.Name, files.uploadCount(), first.Name)
+	}
+}
+
+func TestFileUploaderZeroValue(t *testing.T) {
+	files := newFakeFiles()
+	u := &FileUploader{Client: newTestClient(t, files)}
+	path := writeTempFile(t, "poem.txt", "roses are red")
+	for range 2 {
+		if _, err := u.UploadFromPath(context.Background(), path, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if files.uploadCount() != 1 {
+		t.Errorf("%d uploads, want the second call to reuse the first", files.uploadCount())
+	}
+}
+
+func TestFileUploaderFindsRemoteByHash(t *testing.T) {
+	files := newFakeFiles()
+	for i := range 3 {
+		files.add(&genai.File{Name: "files/other-" + string(rune('a'+i)), State: genai.FileStateActive})
+	}
+	content := []byte("shared content")
+	sum := sha256.Sum256(content)
+	// The API has been seen to encode the hash as base64 of the hex digest.
+	files.add(&genai.File{
+		Name:           "files/existing",
+		Sha256Hash:     base64.StdEncoding.EncodeToString([]byte(hex.EncodeToString(sum[:]))),
+		ExpirationTime: time.Now().Add(24 * time.Hour),
+		State:          genai.FileStateActive,
+	})
+	client := newTestClient(t, files)
+
+	f, err := NewFileUploader(client, "").UploadBytes(context.Background(), content, &genai.UploadFileConfig{MIMEType: "text/plain"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "files/existing" || files.uploadCount() != 0 {
+		t.Errorf("got %s after %d uploads, want files/existing", f.Name, files.uploadCount())
+	}
+}
+
+func TestFileUploaderSkipsExpiredAndDeleted(t *testing.T) {
+	files := newFakeFiles()
+	content := []byte("old content")
+	sum := sha256.Sum256(content)
+	files.add(&genai.File{
+		Name:           "files/expiring",
+		Sha256Hash:     base64.StdEncoding.EncodeToString(sum[:]),
+		ExpirationTime: time.Now().Add(time.Minute),
+		State:          genai.FileStateActive,
+	})
+	client := newTestClient(t, files)
+	u := NewFileUploader(client, filepath.Join(t.TempDir(), "index.json"))
+	cfg := &genai.UploadFileConfig{MIMEType: "text/plain"}
+
+	f, err := u.UploadBytes(context.Background(), content, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name == "files/expiring" || files.uploadCount() != 1 {
+		t.Fatalf("reused a file that is about to expire")
+	}
+	if f.DisplayName != "sha256-"+hex.EncodeToString(sum[:]) {
+		t.Errorf("DisplayName = %q", f.DisplayName)
+	}
+
+	// Once the indexed file is gone remotely it is uploaded again.
+	if _, err := client.Files.Delete(context.Background(), f.Name, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := u.UploadBytes(context.Background(), content, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if files.uploadCount() != 2 {
+		t.Errorf("uploads = %d, want 2", files.uploadCount())
+	}
+}
+
+func TestFilesCreateDeduplicated(t *testing.T) {
+	_, err := FilesCreateDeduplicated()
+	if err != nil {
+		t.Errorf("FilesCreateDeduplicated returned an error: %v", err)
+	}
+}
+
+func TestFileUploaderConcurrentSameContent(t *testing.T) {
+	files := newFakeFiles()
+	client := newTestClient(t, files)
+	u := NewFileUploader(client, filepath.Join(t.TempDir(), "index.json"))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	names := make([]string, 8)
+	for i := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f, err := u.UploadBytes(ctx, []byte("same bytes"), &genai.UploadFileConfig{MIMEType: "text/plain"})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			names[i] = f.Name
+		}()
+	}
+	wg.Wait()
+	if files.uploadCount() != 1 {
+		t.Errorf("uploads = %d, want 1", files.uploadCount())
+	}
+	for _, name := range names {
+		if name != names[0] {
+			t.Errorf("names = %v, want one file", names)
+			break
+		}
+	}
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(u.IndexPath), "*.tmp")); len(matches) > 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
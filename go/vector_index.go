@@ -0,0 +1,750 @@
+package examples
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+
+	"google.golang.org/genai"
+)
+
+// Metric is the similarity measure a VectorIndex ranks by.
+type Metric uint8
+
+const (
+	Cosine Metric = iota
+	DotProduct
+	Euclidean
+)
+
+func (m Metric) String() string {
+	switch m {
+	case Cosine:
+		return "cosine"
+	case DotProduct:
+		return "dot"
+	case Euclidean:
+		return "euclidean"
+	}
+	return fmt.Sprintf("Metric(%d)", uint8(m))
+}
+
+// VectorRecord is a stored embedding.
+type VectorRecord struct {
+	ID       string
+	Vector   []float32
+	Metadata map[string]string
+}
+
+// SearchResult is a record and its similarity to the query. Higher scores
+// are closer; for Euclidean the score is the negated distance.
+type SearchResult struct {
+	VectorRecord
+	Score float32
+}
+
+// HNSWOptions configures an approximate HNSW graph. Zero fields take the
+// defaults from the HNSW paper's recommendations.
+type HNSWOptions struct {
+	// M is the number of links per node above layer 0, which gets 2*M.
+	// Zero means 16; otherwise it must be at least 2.
+	M int
+	// EfConstruction is the candidate list size while inserting. Zero
+	// means 200.
+	EfConstruction int
+	// EfSearch is the candidate list size while searching; raise it for
+	// better recall. Zero means 64.
+	EfSearch int
+	// Seed makes level assignment reproducible.
+	Seed int64
+}
+
+// VectorIndex is an in-memory store of embeddings searchable by similarity.
+// It is safe for concurrent use.
+type VectorIndex struct {
+	dim    int
+	metric Metric
+
+	mu      sync.RWMutex
+	records []VectorRecord
+	norms   []float32
+	byID    map[string]int
+	hnsw    *hnswGraph
+}
+
+// NewVectorIndex returns an index that searches by brute force, which is
+// exact and fast enough for tens of thousands of vectors.
+func NewVectorIndex(dim int, metric Metric) *VectorIndex {
+	return &VectorIndex{dim: dim, metric: metric, byID: make(map[string]int)}
+}
+
+// NewHNSWIndex returns an index that searches an HNSW graph, trading exact
+// results for sublinear search time on large collections.
+func NewHNSWIndex(dim int, metric Metric, opts *HNSWOptions) (*VectorIndex, error) {
+	g, err := newHNSWGraph(opts)
+	if err != nil {
+		return nil, err
+	}
+	ix := NewVectorIndex(dim, metric)
+	ix.hnsw = g
+	return ix, nil
+}
+
+// Dim returns the vector length the index accepts.
+func (ix *VectorIndex) Dim() int { return ix.dim }
+
+// Metric returns the similarity measure of the index.
+func (ix *VectorIndex) Metric() Metric { return ix.metric }
+
+// Len returns the number of records.
+func (ix *VectorIndex) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.records)
+}
+
+// Get returns the record with id.
+func (ix *VectorIndex) Get(id string) (VectorRecord, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	i, ok := ix.byID[id]
+	if !ok {
+		return VectorRecord{}, false
+	}
+	return ix.records[i], true
+}
+
+// Add stores vector under id. IDs must be unique.
+func (ix *VectorIndex) Add(id string, vector []float32, metadata map[string]string) error {
+	if len(vector) != ix.dim {
+		return fmt.Errorf("vector index: %s has %d dimensions, want %d", id, len(vector), ix.dim)
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if _, ok := ix.byID[id]; ok {
+		return fmt.Errorf("vector index: duplicate id %q", id)
+	}
+	ix.addLocked(id, vector, metadata)
+	return nil
+}
+
+// AddBatch stores every record or none: if one has the wrong dimension or an
+// ID that is already taken, or taken twice in records, nothing is added.
+func (ix *VectorIndex) AddBatch(records []VectorRecord) error {
+	for _, r := range records {
+		if len(r.Vector) != ix.dim {
+			return fmt.Errorf("vector index: %s has %d dimensions, want %d", r.ID, len(r.Vector), ix.dim)
+		}
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	seen := make(map[string]bool, len(records))
+	for _, r := range records {
+		if _, ok := ix.byID[r.ID]; ok || seen[r.ID] {
+			return fmt.Errorf("vector index: duplicate id %q", r.ID)
+		}
+		seen[r.ID] = true
+	}
+	for _, r := range records {
+		ix.addLocked(r.ID, r.Vector, r.Metadata)
+	}
+	return nil
+}
+
+func (ix *VectorIndex) addLocked(id string, vector []float32, metadata map[string]string) {
+	ix.byID[id] = len(ix.records)
+	ix.records = append(ix.records, VectorRecord{ID: id, Vector: slices.Clone(vector), Metadata: metadata})
+	ix.norms = append(ix.norms, norm(vector))
+	if ix.hnsw != nil {
+		ix.hnsw.insert(ix, len(ix.records)-1)
+	}
+}
+
+// Search returns the k records most similar to query, best first. k must be
+// positive.
+func (ix *VectorIndex) Search(query []float32, k int) ([]SearchResult, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("vector index: k must be positive, got %d", k)
+	}
+	if len(query) != ix.dim {
+		return nil, fmt.Errorf("vector index: query has %d dimensions, want %d", len(query), ix.dim)
+	}
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	qn := norm(query)
+	var ids []int
+	if ix.hnsw != nil {
+		ids = ix.hnsw.search(ix, query, qn, k)
+	} else {
+		ids = make([]int, len(ix.records))
+		for i := range ids {
+			ids[i] = i
+		}
+	}
+	results := make([]SearchResult, len(ids))
+	for i, id := range ids {
+		results[i] = SearchResult{VectorRecord: ix.records[id], Score: ix.score(query, qn, id)}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results[:min(k, len(results))], nil
+}
+
+// score is the similarity of q, whose norm is qn, to record i.
+func (ix *VectorIndex) score(q []float32, qn float32, i int) float32 {
+	v := ix.records[i].Vector
+	switch ix.metric {
+	case Cosine:
+		if qn == 0 || ix.norms[i] == 0 {
+			return 0
+		}
+		return dot(q, v) / (qn * ix.norms[i])
+	case DotProduct:
+		return dot(q, v)
+	default:
+		var sum float32
+		for j := range q {
+			d := q[j] - v[j]
+			sum += d * d
+		}
+		return -float32(math.Sqrt(float64(sum)))
+	}
+}
+
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func norm(v []float32) float32 {
+	return float32(math.Sqrt(float64(dot(v, v))))
+}
+
+// hnswGraph is a hierarchical navigable small world graph over the records
+// of a VectorIndex, as described by Malkov and Yashunin.
+type hnswGraph struct {
+	m, efConstruction, efSearch int
+	rng                         *rand.Rand
+	entry, maxLevel             int
+	// links[node][level] are the node's neighbours on that level.
+	links [][][]int32
+}
+
+func newHNSWGraph(opts *HNSWOptions) (*hnswGraph, error) {
+	var o HNSWOptions
+	if opts != nil {
+		o = *opts
+	}
+	g := &hnswGraph{m: o.M, efConstruction: o.EfConstruction, efSearch: o.EfSearch, entry: -1}
+	if g.m == 0 {
+		g.m = 16
+	}
+	if g.efConstruction == 0 {
+		g.efConstruction = 200
+	}
+	if g.efSearch == 0 {
+		g.efSearch = 64
+	}
+	// With M below 2 the level distribution is degenerate: M of 1 makes
+	// every level infinite.
+	if g.m < 2 {
+		return nil, fmt.Errorf("vector index: HNSW M must be at least 2, got %d", g.m)
+	}
+	if g.efConstruction < 1 || g.efSearch < 1 {
+		return nil, fmt.Errorf("vector index: HNSW ef must be positive, got %d and %d", g.efConstruction, g.efSearch)
+	}
+	g.rng = rand.New(rand.NewSource(o.Seed))
+	return g, nil
+}
+
+func (g *hnswGraph) maxLinks(level int) int {
+	if level == 0 {
+		return 2 * g.m
+	}
+	return g.m
+}
+
+func (g *hnswGraph) insert(ix *VectorIndex, id int) {
+	q, qn := ix.records[id].Vector, ix.norms[id]
+	level := int(-math.Log(1-g.rng.Float64()) / math.Log(float64(g.m)))
+	g.links = append(g.links, make([][]int32, level+1))
+	if g.entry < 0 {
+		g.entry, g.maxLevel = id, level
+		return
+	}
+	ep := g.entry
+	for l := g.maxLevel; l > level; l-- {
+		ep = g.searchLayer(ix, q, qn, ep, 1, l)[0].id
+	}
+	for l := min(level, g.maxLevel); l >= 0; l-- {
+		found := g.searchLayer(ix, q, qn, ep, g.efConstruction, l)
+		neighbours := make([]int32, 0, g.m)
+		for _, c := range found[:min(g.m, len(found))] {
+			neighbours = append(neighbours, int32(c.id))
+		}
+		g.links[id][l] = neighbours
+		for _, n := range neighbours {
+			g.links[n][l] = append(g.links[n][l], int32(id))
+			if len(g.links[n][l]) > g.maxLinks(l) {
+				g.prune(ix, int(n), l)
+			}
+		}
+		ep = found[0].id
+	}
+	if level > g.maxLevel {
+		g.entry, g.maxLevel = id, level
+	}
+}
+
+// prune keeps the closest maxLinks neighbours of node on level.
+func (g *hnswGraph) prune(ix *VectorIndex, node, level int) {
+	v, vn := ix.records[node].Vector, ix.norms[node]
+	links := g.links[node][level]
+	sort.Slice(links, func(i, j int) bool {
+		return ix.score(v, vn, int(links[i])) > ix.score(v, vn, int(links[j]))
+	})
+	g.links[node][level] = links[:g.maxLinks(level)]
+}
+
+func (g *hnswGraph) search(ix *VectorIndex, q []float32, qn float32, k int) []int {
+	if g.entry < 0 {
+		return nil
+	}
+	ep := g.entry
+	for l := g.maxLevel; l > 0; l-- {
+		ep = g.searchLayer(ix, q, qn, ep, 1, l)[0].id
+	}
+	found := g.searchLayer(ix, q, qn, ep, max(g.efSearch, k), 0)
+	ids := make([]int, 0, min(k, len(found)))
+	for _, c := range found[:min(k, len(found))] {
+		ids = append(ids, c.id)
+	}
+	return ids
+}
+
+type hnswCandidate struct {
+	id    int
+	score float32
+}
+
+// searchLayer returns up to ef nodes on level closest to q, best first.
+func (g *hnswGraph) searchLayer(ix *VectorIndex, q []float32, qn float32, ep, ef, level int) []hnswCandidate {
+	start := hnswCandidate{ep, ix.score(q, qn, ep)}
+	visited := map[int]bool{ep: true}
+	candidates := &candidateHeap{items: []hnswCandidate{start}, best: true}
+	results := &candidateHeap{items: []hnswCandidate{start}}
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(hnswCandidate)
+		if results.Len() >= ef && c.score < results.items[0].score {
+			break
+		}
+		if level >= len(g.links[c.id]) {
+			continue
+		}
+		for _, n := range g.links[c.id][level] {
+			if visited[int(n)] {
+				continue
+			}
+			visited[int(n)] = true
+			s := ix.score(q, qn, int(n))
+			if results.Len() < ef || s > results.items[0].score {
+				heap.Push(candidates, hnswCandidate{int(n), s})
+				heap.Push(results, hnswCandidate{int(n), s})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+	out := results.items
+	sort.Slice(out, func(i, j int) bool { return out[i].score > out[j].score })
+	return out
+}
+
+// candidateHeap pops the best-scoring candidate first if best is set, and
+// the worst first otherwise.
+type candidateHeap struct {
+	items []hnswCandidate
+	best  bool
+}
+
+func (h *candidateHeap) Len() int { return len(h.items) }
+func (h *candidateHeap) Less(i, j int) bool {
+	if h.best {
+		return h.items[i].score > h.items[j].score
+	}
+	return h.items[i].score < h.items[j].score
+}
+func (h *candidateHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *candidateHeap) Push(x any)    { h.items = append(h.items, x.(hnswCandidate)) }
+func (h *candidateHeap) Pop() any {
+	x := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return x
+}
+
+// vectorIndexMagic starts every saved index; the byte after it is the format
+// version.
+const vectorIndexMagic = "GVIX"
+
+// Save writes the index, including any HNSW graph, in a compact binary
+// format: little-endian float32 vectors and varint-prefixed strings.
+func (ix *VectorIndex) Save(w io.Writer) error {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	bw := bufio.NewWriter(w)
+	var buf []byte
+	putUvarint := func(v uint64) { buf = binary.AppendUvarint(buf, v) }
+	putString := func(s string) { putUvarint(uint64(len(s))); buf = append(buf, s...) }
+	flush := func() error {
+		_, err := bw.Write(buf)
+		buf = buf[:0]
+		return err
+	}
+
+	buf = append(buf, vectorIndexMagic...)
+	buf = append(buf, 1, byte(ix.metric))
+	putUvarint(uint64(ix.dim))
+	putUvarint(uint64(len(ix.records)))
+	for _, r := range ix.records {
+		putString(r.ID)
+		keys := make([]string, 0, len(r.Metadata))
+		for k := range r.Metadata {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		putUvarint(uint64(len(keys)))
+		for _, k := range keys {
+			putString(k)
+			putString(r.Metadata[k])
+		}
+		for _, f := range r.Vector {
+			buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(f))
+		}
+		if err := flush(); err != nil {
+			return fmt.Errorf("vector index: save: %w", err)
+		}
+	}
+
+	if g := ix.hnsw; g == nil {
+		buf = append(buf, 0)
+	} else {
+		buf = append(buf, 1)
+		for _, v := range []int{g.m, g.efConstruction, g.efSearch, g.entry + 1, g.maxLevel} {
+			putUvarint(uint64(v))
+		}
+		for _, levels := range g.links {
+			putUvarint(uint64(len(levels)))
+			for _, ns := range levels {
+				putUvarint(uint64(len(ns)))
+				for _, n := range ns {
+					putUvarint(uint64(n))
+				}
+			}
+			if err := flush(); err != nil {
+				return fmt.Errorf("vector index: save: %w", err)
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return fmt.Errorf("vector index: save: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("vector index: save: %w", err)
+	}
+	return nil
+}
+
+// SaveFile writes the index to path.
+func (ix *VectorIndex) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("vector index: save: %w", err)
+	}
+	if err := ix.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Limits on what LoadVectorIndex accepts, so a corrupt file fails instead of
+// exhausting memory. A level above maxHNSWLevel has probability below 2^-64
+// even with M of 2.
+const (
+	maxVectorDim  = 1 << 16
+	maxHNSWLevel  = 64
+	maxHNSWParams = 1 << 20
+)
+
+// indexReader decodes a saved index. After the first error every read
+// returns a zero value and err keeps that error.
+type indexReader struct {
+	br  *bufio.Reader
+	err error
+}
+
+// uvarint reads a value that must not exceed limit.
+func (r *indexReader) uvarint(what string, limit int) int {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(r.br)
+	if err == nil && v > uint64(limit) {
+		err = fmt.Errorf("%s %d exceeds %d", what, v, limit)
+	}
+	if err != nil {
+		r.err = err
+		return 0
+	}
+	return int(v)
+}
+
+// str reads a length-prefixed string. The buffer grows as bytes arrive, so a
+// corrupt length runs into the end of the input rather than allocating it.
+func (r *indexReader) str() string {
+	n := r.uvarint("string length", math.MaxInt32)
+	if r.err != nil {
+		return ""
+	}
+	b, err := io.ReadAll(io.LimitReader(r.br, int64(n)))
+	if err == nil && len(b) < n {
+		err = io.ErrUnexpectedEOF
+	}
+	r.err = err
+	return string(b)
+}
+
+// LoadVectorIndex reads an index written by Save. Sizes and node IDs are
+// checked before use, so a corrupt file returns an error.
+func LoadVectorIndex(r io.Reader) (*VectorIndex, error) {
+	rd := &indexReader{br: bufio.NewReader(r)}
+	head := make([]byte, len(vectorIndexMagic)+2)
+	if _, err := io.ReadFull(rd.br, head); err != nil {
+		return nil, fmt.Errorf("vector index: load: %w", err)
+	}
+	if string(head[:4]) != vectorIndexMagic || head[4] != 1 {
+		return nil, errors.New("vector index: load: not a version 1 index file")
+	}
+	if Metric(head[5]) > Euclidean {
+		return nil, fmt.Errorf("vector index: load: unknown metric %d", head[5])
+	}
+
+	ix := NewVectorIndex(0, Metric(head[5]))
+	ix.dim = rd.uvarint("dimension", maxVectorDim)
+	count := rd.uvarint("record count", math.MaxInt32)
+	raw := make([]byte, 4*ix.dim)
+	for i := 0; i < count && rd.err == nil; i++ {
+		rec := VectorRecord{ID: rd.str()}
+		if n := rd.uvarint("metadata count", math.MaxInt32); n > 0 {
+			rec.Metadata = make(map[string]string)
+			for j := 0; j < n && rd.err == nil; j++ {
+				k := rd.str()
+				rec.Metadata[k] = rd.str()
+			}
+		}
+		if rd.err != nil {
+			break
+		}
+		if _, ok := ix.byID[rec.ID]; ok {
+			rd.err = fmt.Errorf("duplicate id %q", rec.ID)
+			break
+		}
+		if _, rd.err = io.ReadFull(rd.br, raw); rd.err != nil {
+			break
+		}
+		rec.Vector = make([]float32, ix.dim)
+		for j := range rec.Vector {
+			rec.Vector[j] = math.Float32frombits(binary.LittleEndian.Uint32(raw[4*j:]))
+		}
+		ix.byID[rec.ID] = len(ix.records)
+		ix.records = append(ix.records, rec)
+		ix.norms = append(ix.norms, norm(rec.Vector))
+	}
+
+	var hasGraph byte
+	if rd.err == nil {
+		hasGraph, rd.err = rd.br.ReadByte()
+	}
+	if rd.err == nil && hasGraph == 1 {
+		ix.hnsw = rd.hnswGraph(count)
+	}
+	if rd.err != nil {
+		return nil, fmt.Errorf("vector index: load: %w", rd.err)
+	}
+	return ix, nil
+}
+
+// hnswGraph reads the graph of an index with count records. Every link must
+// name a record that has the level it is linked on, and the entry point must
+// have the top level.
+func (r *indexReader) hnswGraph(count int) *hnswGraph {
+	opts := &HNSWOptions{
+		M:              r.uvarint("M", maxHNSWParams),
+		EfConstruction: r.uvarint("efConstruction", maxHNSWParams),
+		EfSearch:       r.uvarint("efSearch", maxHNSWParams),
+	}
+	entry := r.uvarint("entry point", count) - 1
+	maxLevel := r.uvarint("level", maxHNSWLevel)
+	if r.err != nil {
+		return nil
+	}
+	g, err := newHNSWGraph(opts)
+	if err != nil {
+		r.err = err
+		return nil
+	}
+	g.entry, g.maxLevel = entry, maxLevel
+	g.links = make([][][]int32, 0, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		levels := make([][]int32, r.uvarint("level count", g.maxLevel+1))
+		for l := 0; l < len(levels) && r.err == nil; l++ {
+			n := r.uvarint("link count", g.maxLinks(l))
+			ns := make([]int32, 0, n)
+			for range n {
+				ns = append(ns, int32(r.uvarint("node", count-1)))
+			}
+			levels[l] = ns
+		}
+		g.links = append(g.links, levels)
+	}
+	if r.err != nil {
+		return nil
+	}
+
+	if count == 0 {
+		if g.entry >= 0 {
+			r.err = fmt.Errorf("entry point %d in an empty graph", g.entry)
+		}
+		return g
+	}
+	if g.entry < 0 || len(g.links[g.entry]) != g.maxLevel+1 {
+		r.err = fmt.Errorf("entry point %d is not on level %d", g.entry, g.maxLevel)
+		return nil
+	}
+	for i, levels := range g.links {
+		if len(levels) == 0 {
+			r.err = fmt.Errorf("node %d has no levels", i)
+			return nil
+		}
+		for l, ns := range levels {
+			for _, n := range ns {
+				if len(g.links[n]) <= l {
+					r.err = fmt.Errorf("node %d links to %d on level %d, above its top", i, n, l)
+					return nil
+				}
+			}
+		}
+	}
+	return g
+}
+
+// LoadVectorIndexFile reads an index saved with SaveFile.
+func LoadVectorIndexFile(path string) (*VectorIndex, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("vector index: load: %w", err)
+	}
+	defer f.Close()
+	return LoadVectorIndex(f)
+}
+
+// maxEmbedBatch is the most texts the API embeds in one request.
+const maxEmbedBatch = 100
+
+// EmbedTexts embeds texts with model, batching requests as needed. The
+// result has one vector per text, in order.
+func EmbedTexts(ctx context.Context, client *genai.Client, model string, texts []string, config *genai.EmbedContentConfig) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for batch := range slices.Chunk(texts, maxEmbedBatch) {
+		contents := make([]*genai.Content, len(batch))
+		for i, t := range batch {
+			contents[i] = genai.NewContentFromText(t, "user")
+		}
+		resp, err := client.Models.EmbedContent(ctx, model, contents, config)
+		if err != nil {
+			return nil, fmt.Errorf("embed texts: %w", err)
+		}
+		if len(resp.Embeddings) != len(batch) {
+			return nil, fmt.Errorf("embed texts: got %d embeddings for %d texts", len(resp.Embeddings), len(batch))
+		}
+		for _, e := range resp.Embeddings {
+			vectors = append(vectors, e.Values)
+		}
+	}
+	return vectors, nil
+}
+
+func EmbedVectorIndex() ([]SearchResult, error) {
+	// [START embed_vector_index]
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     os.Getenv("GEMINI_API_KEY"),
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: NewRetryingHTTPClient(DefaultRetryPolicy()),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	docs := map[string]string{
+		"life":      "What is the meaning of life?",
+		"woodchuck": "How much wood would a woodchuck chuck?",
+		"brain":     "How does the brain work?",
+	}
+	var ids, texts []string
+	for id, text := range docs {
+		ids, texts = append(ids, id), append(texts, text)
+	}
+	vectors, err := EmbedTexts(ctx, client, "text-embedding-004", texts,
+		&genai.EmbedContentConfig{TaskType: "RETRIEVAL_DOCUMENT"})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	index := NewVectorIndex(len(vectors[0]), Cosine)
+	for i, v := range vectors {
+		if err := index.Add(ids[i], v, map[string]string{"text": texts[i]}); err != nil {
+			log.Fatal(err)
+		}
+	}
+	path := filepath.Join(os.TempDir(), "embeddings.gvix")
+	if err := index.SaveFile(path); err != nil {
+		log.Fatal(err)
+	}
+
+	// Later, load the index and search it with an embedded question.
+	index, err = LoadVectorIndexFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	query, err := EmbedTexts(ctx, client, "text-embedding-004", []string{"neuroscience"},
+		&genai.EmbedContentConfig{TaskType: "RETRIEVAL_QUERY"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	results, err := index.Search(query[0], 2)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, r := range results {
+		slog.Info("Match", "score", r.Score, "id", r.ID, "text", r.Metadata["text"])
+	}
+	// [END embed_vector_index]
+	return results, err
+}
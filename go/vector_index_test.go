@@ -0,0 +1,261 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func randomVectors(n, dim int, seed int64) [][]float32 {
+	rng := rand.New(rand.NewSource(seed))
+	vs := make([][]float32, n)
+	for i := range vs {
+		vs[i] = make([]float32, dim)
+		for j := range vs[i] {
+			vs[i][j] = float32(rng.NormFloat64())
+		}
+	}
+	return vs
+}
+
+func TestVectorIndexMetrics(t *testing.T) {
+	vectors := map[string][]float32{
+		"x":      {1, 0},
+		"long-x": {10, 0},
+		"y":      {0, 1},
+	}
+	query := []float32{2, 0.1}
+	for _, tc := range []struct {
+		metric Metric
+		want   string
+	}{
+		{Cosine, "x"}, // ties with long-x, insertion order wins
+		{DotProduct, "long-x"},
+		{Euclidean, "x"},
+	} {
+		ix := NewVectorIndex(2, tc.metric)
+		for _, id := range []string{"x", "long-x", "y"} {
+			if err := ix.Add(id, vectors[id], nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		res, err := ix.Search(query, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != 2 || res[0].ID != tc.want {
+			t.Errorf("%v: top result %v, want %s", tc.metric, res, tc.want)
+		}
+	}
+
+	ix := NewVectorIndex(2, Cosine)
+	ix.Add("a", []float32{1, 0}, nil)
+	if err := ix.Add("a", []float32{0, 1}, nil); err == nil {
+		t.Error("duplicate id was accepted")
+	}
+	if err := ix.Add("b", []float32{1}, nil); err == nil {
+		t.Error("wrong dimension was accepted")
+	}
+}
+
+func TestVectorIndexAddBatch(t *testing.T) {
+	ix := NewVectorIndex(2, Cosine)
+	ix.Add("a", []float32{1, 0}, nil)
+	for name, batch := range map[string][]VectorRecord{
+		"taken id":     {{ID: "b", Vector: []float32{0, 1}}, {ID: "a", Vector: []float32{1, 1}}},
+		"repeated id":  {{ID: "c", Vector: []float32{0, 1}}, {ID: "c", Vector: []float32{1, 1}}},
+		"wrong length": {{ID: "d", Vector: []float32{0, 1}}, {ID: "e", Vector: []float32{1}}},
+	} {
+		if err := ix.AddBatch(batch); err == nil {
+			t.Errorf("%s: batch was accepted", name)
+		}
+		if ix.Len() != 1 {
+			t.Fatalf("%s: index holds %d records after a failed batch, want 1", name, ix.Len())
+		}
+	}
+	if err := ix.AddBatch([]VectorRecord{{ID: "b", Vector: []float32{0, 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ix.Get("b"); !ok || ix.Len() != 2 {
+		t.Errorf("valid batch not added: %d records", ix.Len())
+	}
+}
+
+func TestHNSWRecall(t *testing.T) {
+	const n, dim, k = 1000, 32, 10
+	vectors := randomVectors(n, dim, 1)
+	exact := NewVectorIndex(dim, Cosine)
+	approx, err := NewHNSWIndex(dim, Cosine, &HNSWOptions{Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range vectors {
+		id := fmt.Sprint(i)
+		exact.Add(id, v, nil)
+		approx.Add(id, v, nil)
+	}
+	hits := 0
+	queries := randomVectors(50, dim, 2)
+	for _, q := range queries {
+		want, _ := exact.Search(q, k)
+		got, _ := approx.Search(q, k)
+		ids := map[string]bool{}
+		for _, r := range want {
+			ids[r.ID] = true
+		}
+		for _, r := range got {
+			if ids[r.ID] {
+				hits++
+			}
+		}
+	}
+	if recall := float64(hits) / float64(len(queries)*k); recall < 0.9 {
+		t.Errorf("recall@%d = %.2f, want at least 0.9", k, recall)
+	}
+}
+
+func TestVectorIndexInvalidArguments(t *testing.T) {
+	ix := NewVectorIndex(2, Cosine)
+	ix.Add("a", []float32{1, 0}, nil)
+	for _, k := range []int{0, -1} {
+		if _, err := ix.Search([]float32{1, 0}, k); err == nil {
+			t.Errorf("Search with k = %d was accepted", k)
+		}
+	}
+	for _, opts := range []HNSWOptions{{M: 1}, {M: -3}, {EfSearch: -1}} {
+		if _, err := NewHNSWIndex(2, Cosine, &opts); err == nil {
+			t.Errorf("NewHNSWIndex(%+v) was accepted", opts)
+		}
+	}
+}
+
+func TestVectorIndexSaveLoad(t *testing.T) {
+	hnsw, err := NewHNSWIndex(8, Cosine, &HNSWOptions{M: 4, Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ix := range []*VectorIndex{NewVectorIndex(8, Euclidean), hnsw} {
+		for i, v := range randomVectors(200, 8, 4) {
+			ix.Add(fmt.Sprintf("doc-%d", i), v, map[string]string{"n": fmt.Sprint(i), "src": "test"})
+		}
+		path := filepath.Join(t.TempDir(), "index.gvix")
+		if err := ix.SaveFile(path); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadVectorIndexFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.Len() != 200 || loaded.Dim() != 8 || loaded.Metric() != ix.Metric() {
+			t.Fatalf("loaded %d records of %d dims by %v", loaded.Len(), loaded.Dim(), loaded.Metric())
+		}
+		if r, _ := loaded.Get("doc-7"); r.Metadata["n"] != "7" {
+			t.Errorf("metadata = %v", r.Metadata)
+		}
+		for _, q := range randomVectors(5, 8, 5) {
+			want, _ := ix.Search(q, 5)
+			got, _ := loaded.Search(q, 5)
+			if fmt.Sprint(want) != fmt.Sprint(got) {
+				t.Errorf("results differ after load:\n%v\n%v", want, got)
+			}
+		}
+	}
+	if _, err := LoadVectorIndex(bytes.NewReader([]byte("nope"))); err == nil {
+		t.Error("loaded garbage")
+	}
+}
+
+// indexFile encodes a version 1 cosine index: uvarints for ints, raw bytes
+// for strings and byte slices.
+func indexFile(fields ...any) []byte {
+	b := []byte(vectorIndexMagic + "\x01\x00")
+	for _, f := range fields {
+		switch f := f.(type) {
+		case int:
+			b = binary.AppendUvarint(b, uint64(f))
+		case string:
+			b = append(b, f...)
+		case []byte:
+			b = append(b, f...)
+		}
+	}
+	return b
+}
+
+func TestLoadVectorIndexCorrupt(t *testing.T) {
+	one := []byte{0, 0, 0x80, 0x3f} // float32 1
+	// graph returns a two-record, one-dimension HNSW index whose graph
+	// header and links can be altered.
+	graph := func(m, entry, maxLevel, link int) []byte {
+		return indexFile(1, 2,
+			1, "a", 0, one,
+			1, "b", 0, one,
+			[]byte{1}, m, 10, 10, entry+1, maxLevel,
+			1, 1, link,
+			1, 1, 0)
+	}
+	if _, err := LoadVectorIndex(bytes.NewReader(graph(2, 0, 0, 1))); err != nil {
+		t.Fatalf("valid graph: %v", err)
+	}
+	for name, data := range map[string][]byte{
+		"huge dimension":     indexFile(1<<40, 1),
+		"huge string":        indexFile(2, 1, 1<<30, "a"),
+		"huge metadata":      indexFile(1, 1, 1, "a", 1<<30),
+		"truncated vector":   indexFile(1, 1, 1, "a", 0, one[:2]),
+		"duplicate id":       indexFile(1, 2, 1, "a", 0, one, 1, "a", 0, one, []byte{0}),
+		"M of one":           graph(1, 0, 0, 1),
+		"link out of range":  graph(2, 0, 0, 2),
+		"entry out of range": graph(2, 2, 0, 1),
+		"level too high":     graph(2, 0, 1, 1),
+		"huge level":         graph(2, 0, 1<<40, 1),
+	} {
+		if _, err := LoadVectorIndex(bytes.NewReader(data)); err == nil {
+			t.Errorf("%s: loaded without error", name)
+		}
+	}
+}
+
+func TestEmbedTextsBatches(t *testing.T) {
+	var batches []int
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Requests []json.RawMessage `json:"requests"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		batches = append(batches, len(req.Requests))
+		embeddings := make([]any, len(req.Requests))
+		for i := range embeddings {
+			embeddings[i] = map[string]any{"values": []float32{float32(len(batches)), float32(i)}}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"embeddings": embeddings})
+	}))
+	texts := make([]string, 250)
+	for i := range texts {
+		texts[i] = fmt.Sprint("text ", i)
+	}
+	vectors, err := EmbedTexts(context.Background(), client, "text-embedding-004", texts, &genai.EmbedContentConfig{TaskType: "RETRIEVAL_DOCUMENT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(batches) != "[100 100 50]" || len(vectors) != 250 {
+		t.Fatalf("batches = %v, %d vectors", batches, len(vectors))
+	}
+	if v := vectors[249]; v[0] != 3 || v[1] != 49 {
+		t.Errorf("last vector = %v, want [3 49]", v)
+	}
+}
+
+func TestEmbedVectorIndex(t *testing.T) {
+	_, err := EmbedVectorIndex()
+	if err != nil {
+		t.Errorf("EmbedVectorIndex returned an error: %v", err)
+	}
+}